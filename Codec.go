@@ -0,0 +1,65 @@
+package qmilvus
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// Codec converts models of type v to and from milvus columns without reflection.
+// it is generated by qmilvus-gen and registered in an init func of the generated file:
+//
+//	//go:generate go run github.com/doptime/qmilvus/cmd/qmilvus-gen -type FooEntity
+//
+// NewCollection picks the registered codec up, BuildColumns and the search and query results use it then
+type Codec[v any] struct {
+	// Fields are the schema fields the codec was generated for, the codec is ignored when the model changed since
+	Fields []*entity.Field
+	// Columns builds one column per field written to milvus, in the order of Fields, as BuildColumns does
+	Columns func(models []v) ([]entity.Column, error)
+	// Models builds count models filled from the returned columns
+	Models func(count int, columns []entity.Column) ([]v, error)
+}
+
+// codecs holds the registered codecs, reflect.Type of v -> *Codec[v]
+var codecs sync.Map
+
+// RegisterCodec registers the codec of models of type v, called by the code generated by qmilvus-gen
+func RegisterCodec[v any](codec *Codec[v]) {
+	codecs.Store(reflect.TypeOf((*v)(nil)).Elem(), codec)
+}
+
+// WithCodec sets the codec of the models, nil falls back to reflection
+func (collection *Collection[v]) WithCodec(codec *Codec[v]) (ret *Collection[v]) {
+	collection.codec = codec
+	return collection
+}
+
+// setCodec picks the registered codec of v, unless it was generated for other fields than the model has now
+func (c *Collection[v]) setCodec() {
+	registered, ok := codecs.Load(reflect.TypeOf((*v)(nil)).Elem())
+	if !ok {
+		return
+	}
+	codec := registered.(*Codec[v])
+	if !sameFields(codec.Fields, c.schemaIn.Fields) {
+		c.logger.Warn("generated codec is stale, falling back to reflection, run go generate", "collection", c.collectionName)
+		return
+	}
+	c.codec = codec
+}
+
+// sameFields reports whether the codec fields a match the schema fields b
+func sameFields(a, b []*entity.Field) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].DataType != b[i].DataType || a[i].PrimaryKey != b[i].PrimaryKey ||
+			a[i].IsPartitionKey != b[i].IsPartitionKey || !reflect.DeepEqual(a[i].TypeParams, b[i].TypeParams) {
+			return false
+		}
+	}
+	return true
+}
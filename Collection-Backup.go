@@ -0,0 +1,535 @@
+package qmilvus
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// backupChunkRows is the count of rows per data file of a backup
+var backupChunkRows = 10000
+
+const (
+	backupVersion      = 1 // version of the archive layout written by Backup
+	backupManifestFile = "manifest.json"
+)
+
+// BackupManifest describes a backup archive, it is stored as manifest.json in the archive directory next to the data files
+type BackupManifest struct {
+	Version     int
+	Collection  string
+	CreatedAt   time.Time
+	Schema      *entity.Schema
+	ShardNum    int32
+	Consistency entity.ConsistencyLevel
+	Properties  map[string]string
+	Partitions  []string // partitions of collections partitioned by key are managed by milvus, only their count is restored
+	Aliases     []string
+	Indexes     []BackupIndex
+	Chunks      []BackupChunk
+}
+
+// BackupIndex is an index of the backed up collection
+type BackupIndex struct {
+	Field  string
+	Name   string
+	Type   entity.IndexType
+	Params map[string]string
+}
+
+// BackupChunk is a data file of a backup: gzipped json lines, as written by Export in FormatJSONL
+type BackupChunk struct {
+	File      string
+	Partition string // empty for collections partitioned by key
+	Rows      int
+	SHA256    string // of the file
+}
+
+// Backup writes the collection to the directory path: its schema, properties, partitions, aliases, indexes,
+// and its rows in data files of backupChunkRows rows with their checksums.
+// the manifest is written last, a directory without manifest.json is an unfinished backup.
+// the dynamic field is not backed up. rows written during the backup may be missed.
+// a tenant handle backs up the rows of its tenant only, with the schema and the indexes of the whole collection
+func (c *Collection[v]) Backup(ctx context.Context, path string) (manifest *BackupManifest, err error) {
+	if _, err = os.Stat(filepath.Join(path, backupManifestFile)); err == nil {
+		return nil, fmt.Errorf("backup %s: %w", path, ErrAlreadyExists)
+	}
+	if err = os.MkdirAll(path, 0o755); err != nil {
+		return nil, err
+	}
+	manifest = &BackupManifest{Version: backupVersion, Collection: c.collectionName, CreatedAt: time.Now().UTC()}
+	if err = c.do(ctx, &operation{name: "backup", idempotent: true}, func(ctx context.Context) error {
+		return c.describeBackup(ctx, manifest)
+	}); err != nil {
+		return nil, err
+	}
+
+	var (
+		fields     = backupFields(manifest.Schema)
+		fieldNames []string
+	)
+	for _, f := range fields {
+		// Restore builds the columns with newColumn, a field it cannot build cannot be restored
+		if _, err = newColumn(f, nil); err != nil {
+			return nil, fmt.Errorf("backup %s: %w", c.collectionName, err)
+		}
+		fieldNames = append(fieldNames, f.Name)
+	}
+	var expr string
+	if c.tenant != nil {
+		expr = c.tenant.scopeExpression("")
+	}
+	// rows are read partition by partition, to be restored in their partition
+	partitions := manifest.Partitions
+	if partitionedByKey(manifest.Schema) {
+		partitions = []string{""}
+	}
+	w := &chunkWriter{dir: path, fields: fields, manifest: manifest}
+	for _, partition := range partitions {
+		var names []string
+		if w.partition = partition; partition != "" {
+			names = []string{partition}
+		}
+		err = c.pageRows(ctx, names, expr, fieldNames, func(resultSet client.ResultSet) error {
+			for i := 0; i < resultSet.Len(); i++ {
+				row := make(map[string]interface{}, len(resultSet))
+				for _, column := range resultSet {
+					if row[column.Name()], err = exportValue(column, i); err != nil {
+						return fmt.Errorf("backup %s row %d: %w", column.Name(), i, err)
+					}
+				}
+				if err = w.Write(row); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err == nil {
+			err = w.Close()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return manifest, writeManifest(path, manifest)
+}
+
+// describeBackup fills the manifest with the description of the collection
+func (c *Collection[v]) describeBackup(ctx context.Context, manifest *BackupManifest) (err error) {
+	_client, err := c.NewGrpcClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer _client.Close()
+	coll, err := _client.DescribeCollection(ctx, c.collectionName)
+	if err != nil {
+		return wrapError(err)
+	}
+	manifest.Schema, manifest.ShardNum, manifest.Consistency, manifest.Properties = coll.Schema, coll.ShardNum, coll.ConsistencyLevel, coll.Properties
+
+	partitions, err := _client.ShowPartitions(ctx, c.collectionName)
+	if err != nil {
+		return wrapError(err)
+	}
+	manifest.Partitions = manifest.Partitions[:0]
+	for _, p := range partitions {
+		manifest.Partitions = append(manifest.Partitions, p.Name)
+	}
+	if manifest.Aliases, err = ListAliases(ctx, _client, c.collectionName); err != nil {
+		return err
+	}
+
+	manifest.Indexes = manifest.Indexes[:0]
+	for _, f := range coll.Schema.Fields {
+		indexes, err := _client.DescribeIndex(ctx, c.collectionName, f.Name)
+		if err = wrapError(err); errors.Is(err, ErrIndexNotFound) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		for _, index := range indexes {
+			manifest.Indexes = append(manifest.Indexes, BackupIndex{Field: f.Name, Name: index.Name(), Type: index.IndexType(), Params: index.Params()})
+		}
+	}
+	return nil
+}
+
+// ListAliases returns the aliases of collection, sorted. c should be a *client.GrpcClient, as NewGrpcClient returns:
+// the aliases are listed by the ListAliases service of milvus, milvus-sdk-go v2.4 does not wrap it
+func ListAliases(ctx context.Context, c client.Client, collection string) ([]string, error) {
+	grpcClient, ok := c.(*client.GrpcClient)
+	if !ok {
+		return nil, fmt.Errorf("listing aliases needs a grpc client, got %T", c)
+	}
+	resp, err := grpcClient.Service.ListAliases(ctx, &milvuspb.ListAliasesRequest{CollectionName: collection})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	aliases := resp.GetAliases()
+	sort.Strings(aliases)
+	return aliases, nil
+}
+
+// ReadBackupManifest reads the manifest of the backup in the directory path
+func ReadBackupManifest(path string) (manifest *BackupManifest, err error) {
+	b, err := os.ReadFile(filepath.Join(path, backupManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &manifest); err != nil {
+		return nil, fmt.Errorf("manifest of backup %s: %w", path, err)
+	}
+	if manifest.Version != backupVersion {
+		return nil, fmt.Errorf("backup %s has version %d, want %d: %w", path, manifest.Version, backupVersion, ErrInvalidArgument)
+	}
+	return manifest, nil
+}
+
+// writeManifest writes the manifest through a temporary file, so it is complete once it exists
+func writeManifest(path string, manifest *BackupManifest) error {
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(path, backupManifestFile+".tmp")
+	if err = os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(path, backupManifestFile))
+}
+
+// backupFields returns the fields of the schema holding data, the dynamic field aside
+func backupFields(schema *entity.Schema) (fields []*entity.Field) {
+	for _, f := range schema.Fields {
+		if !f.IsDynamic {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func partitionedByKey(schema *entity.Schema) bool {
+	for _, f := range schema.Fields {
+		if f.IsPartitionKey {
+			return true
+		}
+	}
+	return false
+}
+
+// chunkWriter writes rows to the data files of a backup, a new file every backupChunkRows rows
+type chunkWriter struct {
+	dir, partition string
+	fields         []*entity.Field
+	manifest       *BackupManifest
+
+	file  *os.File
+	gz    *gzip.Writer
+	hash  hash.Hash
+	out   recordWriter
+	chunk BackupChunk
+}
+
+func (w *chunkWriter) Write(row map[string]interface{}) (err error) {
+	if w.out == nil {
+		w.chunk = BackupChunk{File: fmt.Sprintf("data-%05d.jsonl.gz", len(w.manifest.Chunks)), Partition: w.partition}
+		if w.file, err = os.Create(filepath.Join(w.dir, w.chunk.File)); err != nil {
+			return err
+		}
+		w.hash = sha256.New()
+		w.gz = gzip.NewWriter(io.MultiWriter(w.file, w.hash))
+		if w.out, err = newRecordWriter(w.gz, FormatJSONL, w.fields); err != nil {
+			return err
+		}
+	}
+	if err = w.out.Write(row); err != nil {
+		return err
+	}
+	if w.chunk.Rows++; w.chunk.Rows == backupChunkRows {
+		return w.Close()
+	}
+	return nil
+}
+
+// Close completes the current data file and adds it to the manifest
+func (w *chunkWriter) Close() (err error) {
+	if w.out == nil {
+		return nil
+	}
+	if err = w.out.Close(); err == nil {
+		err = w.gz.Close()
+	}
+	if err == nil {
+		err = w.file.Sync()
+	}
+	if errClose := w.file.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return err
+	}
+	w.chunk.SHA256 = hex.EncodeToString(w.hash.Sum(nil))
+	w.manifest.Chunks = append(w.manifest.Chunks, w.chunk)
+	w.out = nil
+	return nil
+}
+
+// readChunk reads the rows of a data file of the backup in dir, checking its checksum and row count
+func readChunk(dir string, chunk BackupChunk, fields []*entity.Field) (rows []map[string]interface{}, err error) {
+	b, err := os.ReadFile(filepath.Join(dir, chunk.File))
+	if err != nil {
+		return nil, err
+	}
+	if sum := sha256.Sum256(b); hex.EncodeToString(sum[:]) != chunk.SHA256 {
+		return nil, fmt.Errorf("data file %s: checksum mismatch: %w", chunk.File, ErrCorruptBackup)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("data file %s: %w", chunk.File, err)
+	}
+	in, err := newRecordReader(gz, FormatJSONL, fields)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		row, err := in.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("data file %s row %d: %w", chunk.File, len(rows), err)
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) != chunk.Rows {
+		return nil, fmt.Errorf("data file %s has %d rows, want %d: %w", chunk.File, len(rows), chunk.Rows, ErrCorruptBackup)
+	}
+	return rows, nil
+}
+
+// Restore recreates the collection backed up in the directory path as newName, the name of the backup if empty:
+// its schema, properties, partitions, indexes and rows, and its aliases unless they are taken by another collection.
+// the rows restored are recorded in path/restore-<newName>.progress after every batch, calling Restore again after a failure resumes it.
+// Restore fails with ErrAlreadyExists if newName exists and is not a restore in progress.
+// rows are upserted, except in collections with auto id primary keys: their rows are inserted and given new ids,
+// the primary key values of the backup are not kept, and a batch whose insert failed after milvus applied it is inserted again on resume
+func (c *Collection[v]) Restore(ctx context.Context, path, newName string) (err error) {
+	manifest, err := ReadBackupManifest(path)
+	if err != nil {
+		return err
+	}
+	if newName == "" {
+		newName = manifest.Collection
+	}
+	progressPath := filepath.Join(path, "restore-"+newName+".progress")
+	done, err := readProgress(progressPath)
+	if errors.Is(err, os.ErrNotExist) {
+		// a new restore, checked once: a retry of restoreCollection finds the collection it created
+		if err = c.checkRestoreTarget(ctx, newName); err == nil {
+			err = os.WriteFile(progressPath, nil, 0o644)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err = c.do(ctx, &operation{name: "restore", idempotent: true}, func(ctx context.Context) error {
+		return c.restoreCollection(ctx, manifest, newName)
+	}); err != nil {
+		return err
+	}
+
+	progress, err := os.OpenFile(progressPath, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer progress.Close()
+	fields := backupFields(manifest.Schema)
+	for _, chunk := range manifest.Chunks {
+		if done[chunk.File] >= chunk.Rows {
+			continue
+		}
+		rows, err := readChunk(path, chunk, fields)
+		if err != nil {
+			return err
+		}
+		// resumed at the first batch not restored
+		for first := done[chunk.File]; first < len(rows); first += importBatchSize {
+			last := min(first+importBatchSize, len(rows))
+			if err = c.restoreRows(ctx, manifest.Schema, newName, chunk.Partition, rows[first:last]); err != nil {
+				return fmt.Errorf("restore %s row %d: %w", chunk.File, first, err)
+			}
+			if _, err = fmt.Fprintf(progress, "%s %d\n", chunk.File, last); err == nil {
+				err = progress.Sync()
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if err = c.do(ctx, &operation{name: "restore", idempotent: true}, func(ctx context.Context) error {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer _client.Close()
+		for _, alias := range manifest.Aliases {
+			if err = wrapError(_client.CreateAlias(ctx, newName, alias)); errors.Is(err, ErrAlreadyExists) {
+				c.logger.Warn("alias of the backup kept on its collection", "alias", alias, "collection", newName)
+			} else if err != nil {
+				return err
+			}
+		}
+		return wrapError(_client.Flush(ctx, newName, false))
+	}); err != nil {
+		return err
+	}
+	progress.Close()
+	return os.Remove(progressPath)
+}
+
+// checkRestoreTarget fails with ErrAlreadyExists if the collection newName exists
+func (c *Collection[v]) checkRestoreTarget(ctx context.Context, newName string) (err error) {
+	var exists bool
+	err = c.do(ctx, &operation{name: "restore", idempotent: true}, func(ctx context.Context) error {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer _client.Close()
+		exists, err = _client.HasCollection(ctx, newName)
+		return wrapError(err)
+	})
+	if err == nil && exists {
+		err = fmt.Errorf("restore to collection %s: %w", newName, ErrAlreadyExists)
+	}
+	return err
+}
+
+// restoreCollection creates the collection newName with the partitions and indexes of the manifest, keeping those already created
+func (c *Collection[v]) restoreCollection(ctx context.Context, manifest *BackupManifest, newName string) (err error) {
+	_client, err := c.NewGrpcClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer _client.Close()
+	schema := *manifest.Schema
+	schema.CollectionName, schema.Fields = newName, backupFields(manifest.Schema)
+	opts := []client.CreateCollectionOption{client.WithConsistencyLevel(manifest.Consistency)}
+	for k, value := range manifest.Properties {
+		opts = append(opts, client.WithCollectionProperty(k, value))
+	}
+	byKey := partitionedByKey(manifest.Schema)
+	if byKey {
+		opts = append(opts, client.WithPartitionNum(int64(len(manifest.Partitions))))
+	}
+	if err = wrapError(_client.CreateCollection(ctx, &schema, manifest.ShardNum, opts...)); err != nil && !errors.Is(err, ErrAlreadyExists) {
+		return err
+	}
+	for _, partition := range manifest.Partitions {
+		if byKey || partition == "_default" {
+			continue
+		}
+		if err = wrapError(_client.CreatePartition(ctx, newName, partition)); err != nil && !errors.Is(err, ErrAlreadyExists) {
+			return err
+		}
+	}
+	for _, index := range manifest.Indexes {
+		// a resumed restore may have built the index already
+		if _, err = _client.DescribeIndex(ctx, newName, index.Field, client.WithIndexName(index.Name)); err == nil {
+			continue
+		}
+		if err = wrapError(err); !errors.Is(err, ErrIndexNotFound) {
+			return err
+		}
+		params := make(map[string]string, len(index.Params))
+		for k, value := range index.Params {
+			params[k] = value
+		}
+		generic := entity.NewGenericIndex(index.Name, index.Type, params)
+		if err = wrapError(_client.CreateIndex(ctx, newName, index.Field, generic, false, client.WithIndexName(index.Name))); err != nil && !errors.Is(err, ErrAlreadyExists) {
+			return fmt.Errorf("index %s of field %s: %w", index.Name, index.Field, err)
+		}
+	}
+	return nil
+}
+
+// restoreRows writes rows of a data file to the partition of the collection newName
+func (c *Collection[v]) restoreRows(ctx context.Context, schema *entity.Schema, newName, partition string, rows []map[string]interface{}) error {
+	var (
+		columns []entity.Column
+		autoID  bool
+	)
+	for _, field := range backupFields(schema) {
+		if field.PrimaryKey && field.AutoID {
+			autoID = true
+			continue
+		}
+		values := make([]interface{}, len(rows))
+		for i, row := range rows {
+			x, ok := row[field.Name]
+			if !ok {
+				return fmt.Errorf("row %d lacks field %s: %w", i, field.Name, ErrCorruptBackup)
+			}
+			var err error
+			if values[i], err = importValue(field, x); err != nil {
+				return fmt.Errorf("row %d field %s: %w", i, field.Name, err)
+			}
+		}
+		column, err := newColumn(field, values)
+		if err != nil {
+			return err
+		}
+		columns = append(columns, column)
+	}
+	// upserts can be replayed on resume, inserts of auto ids cannot, milvus rejects upserts of them
+	return c.do(ctx, &operation{name: "restore", idempotent: !autoID, rows: len(rows)}, func(ctx context.Context) error {
+		_client, err := c.getClient()
+		if err != nil {
+			return fmt.Errorf("get client failed: %w", err)
+		}
+		if autoID {
+			_, err = _client.Insert(ctx, newName, partition, columns...)
+		} else {
+			_, err = _client.Upsert(ctx, newName, partition, columns...)
+		}
+		return wrapError(err)
+	})
+}
+
+// readProgress reads the count of rows restored of every data file, from the lines "<file> <rows>" appended after every batch
+func readProgress(progressPath string) (done map[string]int, err error) {
+	b, err := os.ReadFile(progressPath)
+	if err != nil {
+		return nil, err
+	}
+	done = map[string]int{}
+	lines := strings.Split(string(b), "\n")
+	// the last line is empty, or cut by a crash: its batch is restored again
+	for _, line := range lines[:len(lines)-1] {
+		file, rows, _ := strings.Cut(line, " ")
+		n, err := strconv.Atoi(rows)
+		if err != nil {
+			return nil, fmt.Errorf("restore progress %s: %q: %w", progressPath, line, ErrCorruptBackup)
+		}
+		done[file] = max(done[file], n)
+	}
+	return done, nil
+}
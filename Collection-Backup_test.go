@@ -0,0 +1,95 @@
+package qmilvus
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBackupChunks(t *testing.T) {
+	defer func(rows int) { backupChunkRows = rows }(backupChunkRows)
+	backupChunkRows = 2
+
+	c := NewCollection[*Article](milvusAdress)
+	dir := t.TempDir()
+	manifest := &BackupManifest{Version: backupVersion, Collection: c.collectionName, Schema: c.schemaIn, Partitions: []string{"_default"}}
+	in := []*Article{
+		{Id: 1, Title: "one", TitleVec: []float32{1, 2, 3, 4}, BodyVec: make([]float32, 8), Hash: []byte{1, 2}},
+		{Id: 2, Title: "two", TitleVec: []float32{0.1, 0.2, 0.3, 0.4}, BodyVec: []float32{0.5, 1, -2, 0, 0.25, 3, 4, 8}, Hash: []byte{3, 4}},
+		{Id: 3, Title: "three", TitleVec: []float32{4, 3, 2, 1}, BodyVec: make([]float32, 8), Hash: []byte{5, 6}},
+	}
+	columns, err := c.BuildColumns(in...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &chunkWriter{dir: dir, partition: "_default", fields: c.schemaIn.Fields, manifest: manifest}
+	for i := range in {
+		row := map[string]interface{}{}
+		for _, column := range columns {
+			row[column.Name()], _ = exportValue(column, i)
+		}
+		if err = w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Chunks) != 2 || manifest.Chunks[0].Rows != 2 || manifest.Chunks[1].Rows != 1 {
+		t.Fatalf("chunks %+v, want 2 rows then 1", manifest.Chunks)
+	}
+	if err = writeManifest(dir, manifest); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadBackupManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read.Chunks, manifest.Chunks) || read.Schema.Fields[3].DataType != c.schemaIn.Fields[3].DataType {
+		t.Errorf("manifest read back differs: %+v", read)
+	}
+
+	var rows []map[string]interface{}
+	for _, chunk := range read.Chunks {
+		chunkRows, err := readChunk(dir, chunk, read.Schema.Fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, chunkRows...)
+	}
+	out, err := c.importModels(rows, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("rows read back differ: %+v", out)
+	}
+
+	// a data file changed after the backup fails its checksum
+	file := filepath.Join(dir, read.Chunks[1].File)
+	b, _ := os.ReadFile(file)
+	b[len(b)/2] ^= 0xff
+	os.WriteFile(file, b, 0o644)
+	if _, err = readChunk(dir, read.Chunks[1], read.Schema.Fields); !errors.Is(err, ErrCorruptBackup) {
+		t.Errorf("corrupt data file should fail, got %v", err)
+	}
+}
+
+func TestRestoreProgress(t *testing.T) {
+	progressPath := filepath.Join(t.TempDir(), "restore-x.progress")
+	if _, err := readProgress(progressPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("a restore not started has no progress, got %v", err)
+	}
+	// the last line was cut by a crash, its batch is restored again
+	os.WriteFile(progressPath, []byte("data-00000.jsonl.gz 1000\ndata-00000.jsonl.gz 1500\ndata-00001.jsonl.gz 1000\ndata-00001.jsonl.gz 20"), 0o644)
+	done, err := readProgress(progressPath)
+	if err != nil || !reflect.DeepEqual(done, map[string]int{"data-00000.jsonl.gz": 1500, "data-00001.jsonl.gz": 1000}) {
+		t.Errorf("progress %v, %v", done, err)
+	}
+	os.WriteFile(progressPath, []byte("data-00000.jsonl.gz\n"), 0o644)
+	if _, err = readProgress(progressPath); !errors.Is(err, ErrCorruptBackup) {
+		t.Errorf("a line without row count should fail, got %v", err)
+	}
+}
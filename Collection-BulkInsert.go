@@ -0,0 +1,349 @@
+package qmilvus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path"
+	"time"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+var (
+	bulkFileRows           = 100000          // rows per bulk insert file, unless set by WithFileRows
+	bulkInsertPollInterval = 2 * time.Second // interval WaitBulkInsert polls the import tasks
+
+	// formats of bulk insert files, by file extension
+	bulkInsertFileExtension = map[Format]string{FormatJSON: ".json", FormatParquet: ".parquet"}
+
+	errBulkWriterClosed = errors.New("bulk writer closed")
+)
+
+// keys of the infos of an import task
+const (
+	bulkInsertInfoFiles        = "files"
+	bulkInsertInfoFailedReason = "failed_reason"
+)
+
+// BulkWriter writes models to the row-based files of milvus bulk import, in FormatJSON or FormatParquet.
+// a file is uploaded to the store every WithFileRows rows, and by Close for the last rows.
+// the models are embedded, validated and scoped to the tenant as Upsert does
+type BulkWriter[v any] struct {
+	c        *Collection[v]
+	store    ObjectStore
+	prefix   string
+	format   Format
+	fileRows int
+
+	file   *os.File
+	out    recordWriter
+	rows   int      // rows of the current file
+	keys   []string // of the files uploaded
+	closed bool
+}
+
+// NewBulkWriter returns a writer of the files of a bulk insert into the collection, uploaded to store as prefix/part-00000.json and so on.
+// the files are imported into one partition, collections with a partitioner fail with ErrInvalidArgument
+func (c *Collection[v]) NewBulkWriter(store ObjectStore, prefix string, format Format) (w *BulkWriter[v], err error) {
+	if err = c.checkBulkInsertPartition(); err != nil {
+		return nil, err
+	}
+	if _, ok := bulkInsertFileExtension[format]; !ok {
+		return nil, fmt.Errorf("bulk insert files are json or parquet, not %q: %w", format, ErrInvalidArgument)
+	}
+	return &BulkWriter[v]{c: c, store: store, prefix: prefix, format: format, fileRows: bulkFileRows}, nil
+}
+
+// WithFileRows sets the count of rows per file, milvus imports a file in one task
+func (w *BulkWriter[v]) WithFileRows(rows int) *BulkWriter[v] {
+	if rows <= 0 {
+		panic(fmt.Errorf("file rows should be positive, got %d", rows))
+	}
+	w.fileRows = rows
+	return w
+}
+
+// Write adds models to the files, models dropped by the validation are reported by a *ValidationError once the others are written.
+// the interceptors of the collection see the models as an OpInsert, milvus imports the files as inserts
+func (w *BulkWriter[v]) Write(ctx context.Context, models ...v) (err error) {
+	if w.closed {
+		return errBulkWriterClosed
+	}
+	_, err = w.c.intercept(ctx, &Request[v]{Op: OpInsert, Models: models}, w.handleWrite)
+	return err
+}
+
+// handleWrite writes req.Models to the files
+func (w *BulkWriter[v]) handleWrite(ctx context.Context, req *Request[v]) (resp *Response[v], err error) {
+	if err = w.c.scopeRequest(req); err != nil {
+		return nil, err
+	}
+	columns, dropped, err := w.c.prepareWrite(ctx, req.Models)
+	if err != nil || columns == nil {
+		return nil, err
+	}
+	for i, count := 0, columnsLen(columns); i < count; i++ {
+		row := make(map[string]interface{}, len(columns))
+		for _, column := range columns {
+			if row[column.Name()], err = exportValue(column, i); err != nil {
+				return nil, fmt.Errorf("bulk insert %s row %d: %w", column.Name(), i, err)
+			}
+		}
+		if err = w.writeRow(ctx, row); err != nil {
+			return nil, err
+		}
+	}
+	if dropped != nil {
+		return nil, dropped
+	}
+	return nil, nil
+}
+
+func (w *BulkWriter[v]) writeRow(ctx context.Context, row map[string]interface{}) (err error) {
+	if w.out == nil {
+		if w.file, err = os.CreateTemp("", "qmilvus-bulk-*"+bulkInsertFileExtension[w.format]); err != nil {
+			return err
+		}
+		if w.out, err = newRecordWriter(w.file, w.format, w.c.schemaIn.Fields); err != nil {
+			w.discard()
+			return err
+		}
+	}
+	if err = w.out.Write(row); err != nil {
+		return err
+	}
+	if w.rows++; w.rows == w.fileRows {
+		return w.upload(ctx)
+	}
+	return nil
+}
+
+// upload completes the current file and puts it to the store
+func (w *BulkWriter[v]) upload(ctx context.Context) (err error) {
+	if w.out == nil {
+		return nil
+	}
+	// the file is dropped even if its upload failed
+	defer w.discard()
+	if err = w.out.Close(); err != nil {
+		return err
+	}
+	size, err := w.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err = w.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	key := path.Join(w.prefix, fmt.Sprintf("part-%05d%s", len(w.keys), bulkInsertFileExtension[w.format]))
+	if err = w.store.Put(ctx, key, w.file, size); err != nil {
+		return fmt.Errorf("upload %s: %w", key, err)
+	}
+	w.keys, w.rows = append(w.keys, key), 0
+	return nil
+}
+
+// discard closes and removes the current file, its rows are not uploaded
+func (w *BulkWriter[v]) discard() {
+	if w.file == nil {
+		return
+	}
+	w.file.Close()
+	os.Remove(w.file.Name())
+	w.file, w.out = nil, nil
+}
+
+// Close uploads the last file and returns the keys of all the files uploaded
+func (w *BulkWriter[v]) Close(ctx context.Context) (keys []string, err error) {
+	if !w.closed {
+		w.closed, err = true, w.upload(ctx)
+	}
+	return w.keys, err
+}
+
+// BulkInsertProgress reports the import tasks of a bulk insert
+type BulkInsertProgress struct {
+	Tasks     []*entity.BulkInsertTaskState
+	Completed int   // tasks completed
+	Failed    int   // tasks failed
+	Rows      int64 // rows imported by the completed tasks, parsed by the others
+	Percent   int   // mean progress of the tasks
+}
+
+// BulkInsertError reports an import task that failed
+type BulkInsertError struct {
+	TaskID int64
+	File   string
+	Reason string
+}
+
+func (e *BulkInsertError) Error() string {
+	return fmt.Sprintf("bulk insert task %d of %s failed: %s", e.TaskID, e.File, e.Reason)
+}
+
+// StartBulkInsert starts an import task of every file, keys of the files in the bucket of milvus,
+// into the partition of the collection. milvus imports row-based files one per task
+func (c *Collection[v]) StartBulkInsert(ctx context.Context, keys ...string) (taskIDs []int64, err error) {
+	if err = c.checkBulkInsertPartition(); err != nil {
+		return nil, err
+	}
+	// a task started twice imports its rows twice, start tasks are not retried
+	err = c.do(ctx, &operation{name: "bulk insert"}, func(ctx context.Context) error {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer _client.Close()
+		for _, key := range keys[len(taskIDs):] {
+			taskID, err := _client.BulkInsert(ctx, c.collectionName, c.partitionName, []string{key})
+			if err != nil {
+				return fmt.Errorf("bulk insert %s: %w", key, wrapError(err))
+			}
+			taskIDs = append(taskIDs, taskID)
+		}
+		return nil
+	})
+	return taskIDs, err
+}
+
+// checkBulkInsertPartition rejects bulk inserts into collections with a partitioner:
+// milvus imports a file into one partition, the models would not reach the partitions named by the partitioner
+func (c *Collection[v]) checkBulkInsertPartition() error {
+	if c.partitioner != nil {
+		return fmt.Errorf("collection %s writes models to the partitions of its partitioner, bulk insert files go to one partition, use Upsert: %w", c.collectionName, ErrInvalidArgument)
+	}
+	return nil
+}
+
+// WaitBulkInsert polls the import tasks until they are all completed or failed, calling progress, if not nil, after every poll.
+// the tasks failed are returned as *BulkInsertError, joined
+func (c *Collection[v]) WaitBulkInsert(ctx context.Context, taskIDs []int64, progress func(*BulkInsertProgress)) (err error) {
+	ticker := time.NewTicker(bulkInsertPollInterval)
+	defer ticker.Stop()
+	for {
+		report := &BulkInsertProgress{}
+		err = c.do(ctx, &operation{name: "bulk insert state", idempotent: true}, func(ctx context.Context) error {
+			_client, err := c.NewGrpcClient(ctx)
+			if err != nil {
+				return err
+			}
+			defer _client.Close()
+			report.Tasks = report.Tasks[:0]
+			for _, taskID := range taskIDs {
+				state, err := _client.GetBulkInsertState(ctx, taskID)
+				if err != nil {
+					return wrapError(err)
+				}
+				report.Tasks = append(report.Tasks, state)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		var failures []error
+		for _, task := range report.Tasks {
+			report.Rows += task.RowCount
+			switch task.State {
+			// persisted rows are readable, milvus goes on building their indexes in background
+			case entity.BulkInsertCompleted, entity.BulkInsertPersisted:
+				report.Completed++
+				report.Percent += 100
+			case entity.BulkInsertFailed, entity.BulkInsertFailedAndCleaned:
+				report.Failed++
+				report.Percent += 100
+				failures = append(failures, &BulkInsertError{TaskID: task.ID, File: task.Infos[bulkInsertInfoFiles], Reason: task.Infos[bulkInsertInfoFailedReason]})
+			default:
+				report.Percent += task.Progress()
+			}
+		}
+		if len(report.Tasks) > 0 {
+			report.Percent /= len(report.Tasks)
+		}
+		if progress != nil {
+			progress(report)
+		}
+		if report.Completed+report.Failed == len(report.Tasks) {
+			return errors.Join(failures...)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("bulk insert of collection %s not completed, %d of %d tasks done: %w", c.collectionName, report.Completed+report.Failed, len(report.Tasks), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// BulkInsert writes models to files in format uploaded to store under prefix, imports them and waits until imported.
+// it suits loads of millions of rows, too slow to upsert, pass a slice as slices.Values(models).
+// progress, if not nil, is called while the files are imported, see WaitBulkInsert. n is the count of rows imported.
+// with ValidateDrop the invalid models are left out, and reported by a *ValidationError joined to the error of the import,
+// its rows counted from the first model of models
+func (c *Collection[v]) BulkInsert(ctx context.Context, store ObjectStore, prefix string, format Format, models iter.Seq[v], progress func(*BulkInsertProgress)) (n int64, err error) {
+	w, err := c.NewBulkWriter(store, prefix, format)
+	if err != nil {
+		return 0, err
+	}
+	// the file not uploaded when a write fails
+	defer w.discard()
+	var (
+		dropped *ValidationError
+		seen    int
+		batch   = make([]v, 0, importBatchSize)
+	)
+	write := func() error {
+		var verr *ValidationError
+		err := w.Write(ctx, batch...)
+		if errors.As(err, &verr) && verr.Dropped {
+			if dropped == nil {
+				dropped = &ValidationError{Dropped: true}
+			}
+			for _, violation := range verr.Violations {
+				violation.Row += seen
+				dropped.Violations = append(dropped.Violations, violation)
+			}
+			err = nil
+		}
+		seen, batch = seen+len(batch), batch[:0]
+		return err
+	}
+	for model := range models {
+		if batch = append(batch, model); len(batch) == importBatchSize {
+			if err = write(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if len(batch) > 0 {
+		if err = write(); err != nil {
+			return 0, err
+		}
+	}
+	keys, err := w.Close(ctx)
+	if err == nil && len(keys) > 0 {
+		n, err = c.importBulkFiles(ctx, keys, progress)
+	}
+	if dropped != nil {
+		return n, errors.Join(err, dropped)
+	}
+	return n, err
+}
+
+// importBulkFiles starts the import of the files keys and waits until imported, n is the count of rows imported
+func (c *Collection[v]) importBulkFiles(ctx context.Context, keys []string, progress func(*BulkInsertProgress)) (n int64, err error) {
+	taskIDs, err := c.StartBulkInsert(ctx, keys...)
+	if err != nil {
+		return 0, err
+	}
+	err = c.WaitBulkInsert(ctx, taskIDs, func(report *BulkInsertProgress) {
+		n = report.Rows
+		if progress != nil {
+			progress(report)
+		}
+	})
+	return n, err
+}
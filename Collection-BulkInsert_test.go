@@ -0,0 +1,125 @@
+package qmilvus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBulkWriter(t *testing.T) {
+	c := NewCollection[*Article](milvusAdress)
+	in := []*Article{
+		{Id: 1, Title: "one", TitleVec: []float32{1, 2, 3, 4}, BodyVec: []float32{1, 0, 0, 0, 0, 0, 0, 0}, Hash: []byte{1, 2}},
+		{Id: 2, Title: "two", TitleVec: []float32{0.1, 0.2, 0.3, 0.4}, BodyVec: []float32{0.5, 1, -2, 0, 0.25, 3, 4, 8}, Hash: []byte{3, 255}},
+		{Id: 3, Title: "three", TitleVec: []float32{4, 3, 2, 1}, BodyVec: []float32{0, 0, 0, 0, 0, 0, 0, 2}, Hash: []byte{5, 6}},
+	}
+	for _, format := range []Format{FormatJSON, FormatParquet} {
+		store := DirStore{Dir: t.TempDir()}
+		w, err := c.NewBulkWriter(store, "bulk/articles", format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.WithFileRows(2)
+		if err = w.Write(context.Background(), in...); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		keys, err := w.Close(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		want := []string{"bulk/articles/part-00000." + string(format), "bulk/articles/part-00001." + string(format)}
+		if !reflect.DeepEqual(keys, want) {
+			t.Fatalf("%s: keys %v, want %v", format, keys, want)
+		}
+		if err = w.Write(context.Background(), in...); !errors.Is(err, errBulkWriterClosed) {
+			t.Errorf("%s: write after close should fail, got %v", format, err)
+		}
+
+		var out []*Article
+		for _, key := range keys {
+			f, err := os.Open(filepath.Join(store.Dir, key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := newRecordReader(f, format, c.schemaIn.Fields)
+			if err != nil {
+				t.Fatalf("%s: %v", format, err)
+			}
+			var rows []map[string]interface{}
+			for {
+				row, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%s: %v", format, err)
+				}
+				rows = append(rows, row)
+			}
+			f.Close()
+			models, err := c.importModels(rows, 0)
+			if err != nil {
+				t.Fatalf("%s: %v", format, err)
+			}
+			out = append(out, models...)
+		}
+		if !reflect.DeepEqual(out, in) {
+			t.Errorf("%s: files hold %+v", format, out)
+		}
+	}
+	if _, err := c.NewBulkWriter(DirStore{}, "", FormatCSV); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("csv bulk files should be rejected, got %v", err)
+	}
+
+	partitioned := NewCollection[*Article](milvusAdress).WithPartitioner(func(a *Article) string { return a.Title })
+	if _, err := partitioned.NewBulkWriter(DirStore{}, "", FormatJSON); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("bulk files of a collection with a partitioner should be rejected, got %v", err)
+	}
+	if _, err := partitioned.StartBulkInsert(context.Background(), "bulk/part-00000.json"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("bulk insert into a collection with a partitioner should be rejected, got %v", err)
+	}
+}
+
+func TestBulkInsertValidation(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	articles := func(n int, invalid func(i int) bool) iter.Seq[*Article] {
+		return func(yield func(*Article) bool) {
+			for i := 0; i < n; i++ {
+				a := &Article{Id: int64(i), TitleVec: []float32{1, 0, 0, 0}, BodyVec: []float32{1, 0, 0, 0, 0, 0, 0, 0}, Hash: []byte{1, 2}}
+				if invalid(i) {
+					a.TitleVec = a.TitleVec[:2]
+				}
+				if !yield(a) {
+					return
+				}
+			}
+		}
+	}
+	store := DirStore{Dir: t.TempDir()}
+
+	// rejected in the second batch: the file of the first one is removed, nothing is uploaded
+	c := NewCollection[*Article](milvusAdress)
+	var verr *ValidationError
+	if _, err := c.BulkInsert(context.Background(), store, "bulk", FormatJSON, articles(importBatchSize+1, func(i int) bool { return i == importBatchSize }), nil); !errors.As(err, &verr) || verr.Dropped {
+		t.Errorf("expected the validation error rejecting the load, got %v", err)
+	}
+	if files, _ := os.ReadDir(tmp); len(files) > 0 {
+		t.Errorf("temp files left: %v", files)
+	}
+	if files, _ := os.ReadDir(store.Dir); len(files) > 0 {
+		t.Errorf("files uploaded: %v", files)
+	}
+
+	// every model dropped: no import, the violations are numbered from the first model
+	c = NewCollection[*Article](milvusAdress).WithValidation(ValidateDrop)
+	n, err := c.BulkInsert(context.Background(), store, "bulk", FormatJSON, articles(importBatchSize+2, func(int) bool { return true }), nil)
+	if !errors.As(err, &verr) || !verr.Dropped || len(verr.Violations) != importBatchSize+2 || verr.Violations[importBatchSize+1].Row != importBatchSize+1 || n != 0 {
+		t.Errorf("expected the dropped rows, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,124 @@
+package qmilvus
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+	"google.golang.org/grpc"
+)
+
+// Consistency is the read consistency of search and query.
+// the zero value ConsistencyDefault means: use the level of the collection
+type Consistency int
+
+const (
+	ConsistencyDefault Consistency = iota
+	ConsistencyStrong
+	ConsistencyBounded
+	ConsistencySession
+	ConsistencyEventually
+)
+
+func (cl Consistency) String() string {
+	switch cl {
+	case ConsistencyStrong:
+		return "Strong"
+	case ConsistencyBounded:
+		return "Bounded"
+	case ConsistencySession:
+		return "Session"
+	case ConsistencyEventually:
+		return "Eventually"
+	}
+	return "Default"
+}
+
+func (cl Consistency) entityLevel() entity.ConsistencyLevel {
+	switch cl {
+	case ConsistencyStrong:
+		return entity.ClStrong
+	case ConsistencySession:
+		return entity.ClSession
+	case ConsistencyEventually:
+		return entity.ClEventually
+	}
+	return entity.ClBounded
+}
+
+// WithConsistency sets the consistency level the collection is created with,
+// it is also the level used by search and query when they do not override it
+func (collection *Collection[v]) WithConsistency(cl Consistency) (ret *Collection[v]) {
+	collection.consistency = cl
+	return collection
+}
+
+// readOptions turns the consistency of a read into sdk options.
+// Session reads are sent with the timestamp of the last write seen by this collection handle,
+// so they are guaranteed to observe it even if the write went through another grpc client
+func (c *Collection[v]) readOptions(cl Consistency) []client.SearchQueryOptionFunc {
+	if cl == ConsistencyDefault {
+		cl = c.consistency
+	}
+	if cl == ConsistencyDefault {
+		return nil
+	}
+	if cl != ConsistencySession {
+		return []client.SearchQueryOptionFunc{client.WithSearchQueryConsistencyLevel(cl.entityLevel())}
+	}
+	ts := c.SessionTimestamp()
+	if ts == 0 {
+		// nothing written yet in this session
+		return []client.SearchQueryOptionFunc{client.WithSearchQueryConsistencyLevel(entity.ClEventually)}
+	}
+	return []client.SearchQueryOptionFunc{
+		client.WithSearchQueryConsistencyLevel(entity.ClCustomized),
+		client.WithGuaranteeTimestamp(ts),
+	}
+}
+
+// SessionTimestamp returns the timestamp of the latest Upsert/Remove done through this collection handle
+func (c *Collection[v]) SessionTimestamp() uint64 {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+	return c.sessionTs
+}
+
+// observeSessionTimestamp moves the session forward, never backwards
+func (c *Collection[v]) observeSessionTimestamp(ts uint64) {
+	c.sessionMu.Lock()
+	if ts > c.sessionTs {
+		c.sessionTs = ts
+	}
+	c.sessionMu.Unlock()
+}
+
+// SessionToken exports the session, so another service can read its own writes from here,
+// pass it to ResumeSession on the other side
+func (c *Collection[v]) SessionToken() string {
+	return strconv.FormatUint(c.SessionTimestamp(), 10)
+}
+
+// ResumeSession continues a session exported by SessionToken.
+// Session reads of this handle will then observe every write made before the token was exported
+func (c *Collection[v]) ResumeSession(token string) error {
+	ts, err := strconv.ParseUint(token, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid session token %q: %w", token, err)
+	}
+	c.observeSessionTimestamp(ts)
+	return nil
+}
+
+// sessionInterceptor records the timestamp of every successful write made through the grpc client
+func (c *Collection[v]) sessionInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if result, ok := reply.(*milvuspb.MutationResult); ok && err == nil && result.GetStatus().GetErrorCode() == commonpb.ErrorCode_Success {
+		c.observeSessionTimestamp(result.GetTimestamp())
+	}
+	return err
+}
@@ -0,0 +1,28 @@
+package qmilvus
+
+import (
+	"testing"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+)
+
+func TestSessionToken(t *testing.T) {
+	writer := NewCollection[*OggAction](milvusAdress).WithConsistency(ConsistencySession)
+	writer.observeSessionTimestamp(42)
+	writer.observeSessionTimestamp(7) // never goes backwards
+
+	reader := NewCollection[*OggAction](milvusAdress)
+	if err := reader.ResumeSession(writer.SessionToken()); err != nil {
+		t.Fatal(err)
+	}
+	opt := &client.SearchQueryOption{}
+	for _, o := range reader.readOptions(ConsistencySession) {
+		o(opt)
+	}
+	if opt.GuaranteeTimestamp != 42 {
+		t.Errorf("guarantee timestamp = %d, want 42", opt.GuaranteeTimestamp)
+	}
+	if err := reader.ResumeSession("not-a-token"); err == nil {
+		t.Error("expected error on malformed token")
+	}
+}
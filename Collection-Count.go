@@ -0,0 +1,267 @@
+package qmilvus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// Count returns the count of rows matching expr, all rows if expr is empty, by a count(*) query.
+// the count is exact, deleted rows excluded, but the collection is loaded to run it. a tenant handle counts the rows of its tenant
+func (c *Collection[v]) Count(ctx context.Context, expr string) (n int64, err error) {
+	resp, err := c.intercept(ctx, &Request[v]{Op: OpCount, Expression: expr}, c.handleCount)
+	if err != nil || resp == nil {
+		return 0, err
+	}
+	return resp.Count, nil
+}
+
+// handleCount counts the rows matching req.Expression
+func (c *Collection[v]) handleCount(ctx context.Context, req *Request[v]) (resp *Response[v], err error) {
+	if err = c.scopeRequest(req); err != nil {
+		return nil, err
+	}
+	resp = &Response[v]{}
+	op := &operation{name: "count", idempotent: true}
+	err = c.do(ctx, op, func(ctx context.Context) error {
+		_client, err := c.getClient()
+		if err != nil {
+			return fmt.Errorf("get client failed: %w", err)
+		}
+		if err = _client.LoadCollection(ctx, c.collectionName, false); err != nil {
+			return wrapError(err)
+		}
+		resultSet, err := _client.Query(ctx, c.collectionName, c.partitions(), req.Expression, []string{"count(*)"}, c.readOptions(ConsistencyDefault)...)
+		if err != nil {
+			return wrapError(err)
+		}
+		column := resultSet.GetColumn("count(*)")
+		if column == nil {
+			return fmt.Errorf("count(*) of collection %s not returned", c.collectionName)
+		}
+		resp.Count, err = column.GetAsInt64(0)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ApproximateCount returns the row count of the statistics of the collection, or of its partition if set:
+// cheap, no load needed, but deleted rows are counted until compacted, and the rows written last may be missed until flushed.
+// the statistics span all the tenants, a tenant handle fails with ErrInvalidArgument, use Count
+func (c *Collection[v]) ApproximateCount(ctx context.Context) (n int64, err error) {
+	if c.tenant != nil {
+		return 0, fmt.Errorf("approximate count of collection %s spans all the tenants, use Count on a tenant handle: %w", c.collectionName, ErrInvalidArgument)
+	}
+	err = c.do(ctx, &operation{name: "approximate count", idempotent: true}, func(ctx context.Context) error {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer _client.Close()
+		if c.partitionName != "" {
+			n, err = partitionRowCount(ctx, _client, c.collectionName, c.partitionName)
+			return err
+		}
+		stats, err := _client.GetCollectionStatistics(ctx, c.collectionName)
+		if err != nil {
+			return wrapError(err)
+		}
+		n, err = strconv.ParseInt(stats["row_count"], 10, 64)
+		return err
+	})
+	return n, err
+}
+
+// partitionRowCount returns the row count of the statistics of a partition, from the GetPartitionStatistics service
+func partitionRowCount(ctx context.Context, c client.Client, collection, partition string) (int64, error) {
+	grpcClient, ok := c.(*client.GrpcClient)
+	if !ok {
+		return 0, fmt.Errorf("partition statistics need a grpc client, got %T", c)
+	}
+	resp, err := grpcClient.Service.GetPartitionStatistics(ctx, &milvuspb.GetPartitionStatisticsRequest{CollectionName: collection, PartitionName: partition})
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return strconv.ParseInt(entity.KvPairsMap(resp.GetStats())["row_count"], 10, 64)
+}
+
+// CollectionStats reports the size of the collection, its partitions and its indexes
+type CollectionStats struct {
+	Rows       int64 // approximate, see ApproximateCount
+	LoadState  entity.LoadState
+	Partitions []PartitionStats
+	Indexes    []IndexStats
+
+	// MemoryBytes estimates the memory the loaded collection takes in the query nodes, one replica:
+	// the scalar fields, varchar counted at their max length, and the vector indexes, from the row count and the schema
+	MemoryBytes int64
+}
+
+// PartitionStats reports a partition of the collection
+type PartitionStats struct {
+	Name   string
+	Rows   int64 // approximate, see ApproximateCount
+	Loaded bool
+}
+
+// IndexStats reports an index of the collection and its build progress
+type IndexStats struct {
+	Field       string
+	Name        string
+	Type        entity.IndexType
+	Params      map[string]string
+	State       entity.IndexState
+	TotalRows   int64
+	IndexedRows int64
+}
+
+// Stats reports the row counts of the collection and its partitions, the build progress of its indexes and an estimate of its memory footprint.
+// it needs no load, the row counts are those of the statistics, use Count for exact counts.
+// the statistics span all the tenants, a tenant handle fails with ErrInvalidArgument
+func (c *Collection[v]) Stats(ctx context.Context) (stats *CollectionStats, err error) {
+	if c.tenant != nil {
+		return nil, fmt.Errorf("stats of collection %s span all the tenants: %w", c.collectionName, ErrInvalidArgument)
+	}
+	err = c.do(ctx, &operation{name: "stats", idempotent: true}, func(ctx context.Context) (err error) {
+		stats, err = c.stats(ctx)
+		return err
+	})
+	return stats, err
+}
+
+func (c *Collection[v]) stats(ctx context.Context) (stats *CollectionStats, err error) {
+	_client, err := c.NewGrpcClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer _client.Close()
+	stats = &CollectionStats{}
+	coll, err := _client.DescribeCollection(ctx, c.collectionName)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	collStats, err := _client.GetCollectionStatistics(ctx, c.collectionName)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if stats.Rows, err = strconv.ParseInt(collStats["row_count"], 10, 64); err != nil {
+		return nil, err
+	}
+	if stats.LoadState, err = _client.GetLoadState(ctx, c.collectionName, nil); err != nil {
+		return nil, wrapError(err)
+	}
+
+	partitions, err := _client.ShowPartitions(ctx, c.collectionName)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	for _, p := range partitions {
+		ps := PartitionStats{Name: p.Name, Loaded: p.Loaded}
+		if ps.Rows, err = partitionRowCount(ctx, _client, c.collectionName, p.Name); err != nil {
+			return nil, err
+		}
+		stats.Partitions = append(stats.Partitions, ps)
+	}
+
+	indexes := map[string]entity.Index{}
+	for _, f := range coll.Schema.Fields {
+		described, err := _client.DescribeIndex(ctx, c.collectionName, f.Name)
+		if err = wrapError(err); errors.Is(err, ErrIndexNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		for _, index := range described {
+			is := IndexStats{Field: f.Name, Name: index.Name(), Type: index.IndexType(), Params: index.Params()}
+			if is.State, err = _client.GetIndexState(ctx, c.collectionName, f.Name, client.WithIndexName(index.Name())); err != nil {
+				return nil, wrapError(err)
+			}
+			if is.TotalRows, is.IndexedRows, err = _client.GetIndexBuildProgress(ctx, c.collectionName, f.Name, client.WithIndexName(index.Name())); err != nil {
+				return nil, wrapError(err)
+			}
+			stats.Indexes = append(stats.Indexes, is)
+			indexes[f.Name] = index
+		}
+	}
+	stats.MemoryBytes = stats.Rows * estimateRowBytes(coll.Schema, indexes)
+	return stats, nil
+}
+
+// estimateRowBytes estimates the memory a row takes once loaded: the scalar fields, and the vector fields by their index if any
+func estimateRowBytes(schema *entity.Schema, indexes map[string]entity.Index) (n int64) {
+	for _, f := range schema.Fields {
+		dim, _ := strconv.ParseInt(f.TypeParams[entity.TypeParamDim], 10, 64)
+		switch f.DataType {
+		case entity.FieldTypeBool, entity.FieldTypeInt8:
+			n++
+		case entity.FieldTypeInt16:
+			n += 2
+		case entity.FieldTypeInt32, entity.FieldTypeFloat:
+			n += 4
+		case entity.FieldTypeInt64, entity.FieldTypeDouble:
+			n += 8
+		case entity.FieldTypeVarChar, entity.FieldTypeString:
+			maxLength, _ := strconv.ParseInt(f.TypeParams[entity.TypeParamMaxLength], 10, 64)
+			n += maxLength
+		case entity.FieldTypeFloatVector:
+			n += estimateVectorBytes(dim*4, dim, indexes[f.Name])
+		case entity.FieldTypeFloat16Vector, entity.FieldTypeBFloat16Vector:
+			n += estimateVectorBytes(dim*2, dim, indexes[f.Name])
+		case entity.FieldTypeBinaryVector:
+			n += estimateVectorBytes(dim/8, dim, indexes[f.Name])
+		}
+	}
+	return n
+}
+
+// estimateVectorBytes estimates the memory of a vector of raw bytes once indexed by index, the raw vector when not indexed
+func estimateVectorBytes(raw, dim int64, index entity.Index) int64 {
+	if index == nil {
+		return raw
+	}
+	params := index.Params()
+	switch index.IndexType() {
+	case entity.IvfSQ8:
+		// a byte per dimension
+		return dim
+	case entity.IvfPQ:
+		// m codes of nbits, 8 by default
+		m, _ := strconv.ParseInt(indexParam(params, "m"), 10, 64)
+		if m == 0 {
+			return raw / 4
+		}
+		return m
+	case entity.HNSW, entity.AUTOINDEX:
+		// the vectors and the links of the graph, 2*M neighbours on the bottom layer
+		m, _ := strconv.ParseInt(indexParam(params, "M"), 10, 64)
+		if m == 0 {
+			m = 16
+		}
+		return raw + 2*m*8
+	case entity.DISKANN:
+		// the vectors stay on disk, their quantized codes are loaded
+		return raw / 8
+	}
+	return raw
+}
+
+// indexParam returns a build param of an index as described by milvus, either a key of its own or in the json of "params"
+func indexParam(params map[string]string, key string) string {
+	if value, ok := params[key]; ok {
+		return value
+	}
+	var build map[string]interface{}
+	if json.Unmarshal([]byte(params["params"]), &build) != nil || build[key] == nil {
+		return ""
+	}
+	return fmt.Sprint(build[key])
+}
@@ -0,0 +1,41 @@
+package qmilvus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+func TestEstimateRowBytes(t *testing.T) {
+	c := NewCollection[*Article](milvusAdress)
+	// Id 8, Title 256, TitleVec 4 floats, BodyVec 8 halves, Hash 16 bits
+	if n := estimateRowBytes(c.schemaIn, nil); n != 8+256+16+16+2 {
+		t.Errorf("raw row bytes = %d, want 298", n)
+	}
+	hnsw, _ := entity.NewIndexHNSW(entity.L2, 32, 200)
+	sq8, _ := entity.NewIndexIvfSQ8(entity.L2, 128)
+	indexes := map[string]entity.Index{"TitleVec": hnsw, "BodyVec": sq8}
+	if n := estimateRowBytes(c.schemaIn, indexes); n != 8+256+(16+2*32*8)+8+2 {
+		t.Errorf("indexed row bytes = %d, want %d", n, 8+256+(16+2*32*8)+8+2)
+	}
+	// milvus describes the build params either flat or in the json of params
+	flat := entity.NewGenericIndex("TitleVec", entity.HNSW, map[string]string{"M": "8"})
+	if m := indexParam(flat.Params(), "M"); m != "8" {
+		t.Errorf("flat M = %q", m)
+	}
+	if m := indexParam(hnsw.Params(), "M"); m != "32" {
+		t.Errorf("json M = %q", m)
+	}
+}
+
+func TestStatsOfTenant(t *testing.T) {
+	handle := NewCollection[*TenantDoc](milvusAdress).ForTenant("acme")
+	if _, err := handle.ApproximateCount(context.Background()); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("approximate count of a tenant handle should fail, got %v", err)
+	}
+	if _, err := handle.Stats(context.Background()); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("stats of a tenant handle should fail, got %v", err)
+	}
+}
@@ -1,51 +1,75 @@
 package qmilvus
 
 import (
-	"strings"
+	"context"
+	"errors"
+	"fmt"
 
 	"github.com/milvus-io/milvus-sdk-go/v2/client"
 	"github.com/milvus-io/milvus-sdk-go/v2/entity"
-	"github.com/rs/zerolog/log"
 )
 
 // CreateCollection : try to create a collection, if it already exists, do nothing
 // CreateCollection Only needs to be called once ever
-// if you want to remove the collection ,just rename the collection name, and remove manually in attu
+// if you want to remove the collection ,just rename the collection name, and remove it with `qmilvus drop` or in attu
+// CreateCollection panics if milvus fails, use Create to handle the error
 func (c *Collection[v]) CreateCollection() (ret *Collection[v]) {
-	var (
-		_client    client.Client
-		indexState entity.IndexState
-		err        error
-	)
-	if _client, err = c.NewGrpcClient(c.ctx); err != nil {
-		log.Panic().Str("cannot connect milvus", c.milvusAddress)
+	if err := c.Create(c.ctx); err != nil {
+		c.logger.Error("cannot create milvus collection", "collection", c.collectionName, "error", err)
+		panic(err)
+	}
+	return c
+}
+
+// Create creates the collection, its partition and the indexes of its vector fields, the parts already existing are kept
+func (c *Collection[v]) Create(ctx context.Context) (err error) {
+	return c.do(ctx, &operation{name: "create", idempotent: true}, c.create)
+}
+
+func (c *Collection[v]) create(ctx context.Context) (err error) {
+	var _client client.Client
+	if _client, err = c.NewGrpcClient(ctx); err != nil {
+		return err
 	}
 	defer _client.Close()
 
-	if err = _client.CreateCollection(c.ctx, c.schemaIn, 1); err != nil {
-		//if err string do not contain "already exists",return err
-		if !strings.Contains(err.Error(), "already exist") {
-			log.Panic().Err(err)
-		}
+	var opts []client.CreateCollectionOption
+	if c.consistency != ConsistencyDefault {
+		opts = append(opts, client.WithConsistencyLevel(c.consistency.entityLevel()))
+	}
+	if err = wrapError(_client.CreateCollection(ctx, c.schemaIn, 1, opts...)); err != nil && !errors.Is(err, ErrAlreadyExists) {
+		return err
 	}
-	//create partition
-	if err = _client.CreatePartition(c.ctx, c.collectionName, c.partitionName); err != nil {
-		//if err string do not contain "already exists",return err
-		if !strings.Contains(err.Error(), "already exists") {
-			log.Panic().Err(err)
+	//create partition, collections partitioned by key manage their partitions
+	if c.partitionName != "" {
+		if err = wrapError(_client.CreatePartition(ctx, c.collectionName, c.partitionName)); err != nil && !errors.Is(err, ErrAlreadyExists) {
+			return err
 		}
 	}
-	//Auto BuildIndex
-	if c.IndexFieldName != "" && c.Index != nil {
-		if indexState, err = _client.GetIndexState(c.ctx, c.collectionName, c.IndexFieldName); err != nil {
-			log.Panic().Err(err)
+	//Auto BuildIndex, on every vector field: milvus loads a collection only when all its vectors are indexed
+	for _, field := range c.schemaIn.Fields {
+		if !isVector(field.DataType) {
+			continue
+		}
+		var (
+			indexState entity.IndexState
+			index      entity.Index
+		)
+		if indexState, err = _client.GetIndexState(ctx, c.collectionName, field.Name); err != nil {
+			if err = wrapError(err); !errors.Is(err, ErrIndexNotFound) {
+				return err
+			}
 		}
 		//no index exists, create index
 		if indexState == 0 {
-			if err = _client.CreateIndex(c.ctx, c.collectionName, c.IndexFieldName, c.Index, false); err != nil {
-				log.Panic().Err(err)
+			if index, err = c.fieldIndex(field); err != nil {
+				return fmt.Errorf("index of field %s: %w", field.Name, err)
+			}
+			if err = _client.CreateIndex(ctx, c.collectionName, field.Name, index, false); err != nil {
+				return wrapError(err)
 			}
+			c.ResetIndexCache()
 		}
 	}
-	return c
+	return nil
 }
@@ -1,72 +1,129 @@
 package qmilvus
 
 import (
+	"context"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/milvus-io/milvus-sdk-go/v2/entity"
 )
 
 // remove Milvus collection item using DeleteByPks
 func (c *Collection[v]) RemoveByKeysI64(ids ...int64) (err error) {
-	milvuslient, errM := c.NewGrpcClient(c.ctx)
-	if errM != nil {
-		return errM
-	}
-	defer milvuslient.Close()
-	return milvuslient.DeleteByPks(c.ctx, c.collectionName, c.partitionName, entity.NewColumnInt64("Id", ids))
+	_, err = c.intercept(c.ctx, &Request[v]{Op: OpRemove, Keys: entity.NewColumnInt64(c.pkFieldName, ids)}, c.handleRemove)
+	return err
 }
 
 // remove Milvus collection item using DeleteByPks
 func (c *Collection[v]) RemoveByKeysString(ids ...string) (err error) {
-	milvuslient, errM := c.NewGrpcClient(c.ctx)
-	if errM != nil {
-		return errM
-	}
-	defer milvuslient.Close()
-	return milvuslient.DeleteByPks(c.ctx, c.collectionName, c.partitionName, entity.NewColumnString("Id", ids))
+	_, err = c.intercept(c.ctx, &Request[v]{Op: OpRemove, Keys: entity.NewColumnVarChar(c.pkFieldName, ids)}, c.handleRemove)
+	return err
 }
+
+// Remove deletes the rows of the primary keys of values
 func (c *Collection[v]) Remove(values ...v) (err error) {
-	milvuslient, errM := c.NewGrpcClient(c.ctx)
-	if errM != nil {
-		return errM
-	}
-	defer milvuslient.Close()
+	_, err = c.intercept(c.ctx, &Request[v]{Op: OpRemove, Models: values}, c.handleRemove)
+	return err
+}
+
+// RemoveByExpression deletes the rows matching the boolean expression expr, e.g. `Score < 0.5`
+func (c *Collection[v]) RemoveByExpression(expr string) (err error) {
+	_, err = c.intercept(c.ctx, &Request[v]{Op: OpRemove, Expression: expr}, c.handleRemove)
+	return err
+}
 
-	//take name of type v as collection name
-	_type := reflect.TypeOf((*v)(nil))
-	for _type.Kind() == reflect.Ptr || _type.Kind() == reflect.Slice {
-		_type = _type.Elem()
+// handleRemove deletes the rows of req.Keys and of the primary keys of req.Models,
+// restricted to the rows matching req.Expression when it is set
+func (c *Collection[v]) handleRemove(ctx context.Context, req *Request[v]) (resp *Response[v], err error) {
+	ids := req.Keys
+	if len(req.Models) > 0 {
+		if ids, err = c.pkColumn(req.Models); err != nil {
+			return nil, err
+		}
+	}
+	if (ids == nil || ids.Len() == 0) && req.Expression == "" {
+		// nothing to remove, a tenant handle must not turn it into removing the whole tenant
+		return nil, nil
+	}
+	if err = c.scopeRequest(req); err != nil {
+		return nil, err
+	}
+	if req.Expression == "" {
+		return nil, c.deleteByPks(ctx, ids)
+	}
+	expr := req.Expression
+	if ids != nil {
+		if ids.Len() == 0 {
+			return nil, nil
+		}
+		pkExpr, err := pkInExpression(ids)
+		if err != nil {
+			return nil, err
+		}
+		expr = pkExpr + " and (" + expr + ")"
 	}
-	// get field name of type v
-	pkField, ok := _type.FieldByName(c.pkFieldName)
+	return nil, c.deleteByExpression(ctx, expr)
+}
+
+// deleteByPks deletes the rows of the primary keys in ids, retried as deletes are idempotent
+func (c *Collection[v]) deleteByPks(ctx context.Context, ids entity.Column) (err error) {
+	return c.do(ctx, &operation{name: "delete", idempotent: true, rows: ids.Len()}, func(ctx context.Context) error {
+		milvuslient, errM := c.NewGrpcClient(ctx)
+		if errM != nil {
+			return errM
+		}
+		defer milvuslient.Close()
+		return wrapError(milvuslient.DeleteByPks(ctx, c.collectionName, c.partitionName, ids))
+	})
+}
+
+// deleteByExpression deletes the rows matching expr
+func (c *Collection[v]) deleteByExpression(ctx context.Context, expr string) (err error) {
+	return c.do(ctx, &operation{name: "delete", idempotent: true}, func(ctx context.Context) error {
+		milvuslient, errM := c.NewGrpcClient(ctx)
+		if errM != nil {
+			return errM
+		}
+		defer milvuslient.Close()
+		return wrapError(milvuslient.Delete(ctx, c.collectionName, c.partitionName, expr))
+	})
+}
+
+// pkColumn returns the column of the primary keys of values
+func (c *Collection[v]) pkColumn(values []v) (ids entity.Column, err error) {
+	pk, ok := c.plan.fields[c.pkFieldName]
 	if !ok {
-		return fmt.Errorf("PrimaryKey field %s not found in type %s", c.pkFieldName, _type.Name())
-	} else if pkField.Type.Kind() == reflect.Int64 {
-		ids := make([]int64, 0)
-		for _, v := range values {
-			vv := reflect.ValueOf(v)
-			for vv.Kind() == reflect.Ptr || vv.Kind() == reflect.Slice {
-				vv = vv.Elem()
-			}
-			// get field value of type v
-			fieldValue := vv.FieldByName(c.pkFieldName)
-			ids = append(ids, fieldValue.Int())
+		return nil, fmt.Errorf("PrimaryKey field not found in type %s: %w", c.plan.structType.Name(), ErrInvalidArgument)
+	}
+	rows := c.plan.rows(reflect.ValueOf(&values).Elem())
+	for i, row := range rows {
+		if !row.IsValid() {
+			return nil, fmt.Errorf("row %d: %w: %w", i, errNilModel, ErrInvalidArgument)
+		}
+	}
+	return pk.build(rows)
+}
+
+// pkInExpression returns the expression `Pk in [...]` matching the primary keys in ids
+func pkInExpression(ids entity.Column) (string, error) {
+	keys := make([]string, 0, ids.Len())
+	switch col := ids.(type) {
+	case *entity.ColumnInt64:
+		for _, id := range col.Data() {
+			keys = append(keys, strconv.FormatInt(id, 10))
+		}
+	case *entity.ColumnVarChar:
+		for _, id := range col.Data() {
+			keys = append(keys, strconv.Quote(id))
 		}
-		return milvuslient.DeleteByPks(c.ctx, c.collectionName, c.partitionName, entity.NewColumnInt64(c.pkFieldName, ids))
-	} else if pkField.Type.Kind() == reflect.String {
-		ids := make([]string, 0)
-		for _, v := range values {
-			vv := reflect.ValueOf(v)
-			for vv.Kind() == reflect.Ptr || vv.Kind() == reflect.Slice {
-				vv = vv.Elem()
-			}
-			// get field value of type v
-			fieldValue := vv.FieldByName(c.pkFieldName)
-			ids = append(ids, fieldValue.String())
+	case *entity.ColumnString:
+		for _, id := range col.Data() {
+			keys = append(keys, strconv.Quote(id))
 		}
-		return milvuslient.DeleteByPks(c.ctx, c.collectionName, c.partitionName, entity.NewColumnVarChar(c.pkFieldName, ids))
-	} else {
-		return fmt.Errorf("PrimaryKey in field %s type %s not supported. Type Should be int64 or string", c.pkFieldName, pkField.Type.Kind())
+	default:
+		return "", fmt.Errorf("primary key column %s of type %s not supported: %w", ids.Name(), ids.Type(), ErrInvalidArgument)
 	}
+	return fmt.Sprintf("%s in [%s]", ids.Name(), strings.Join(keys, ",")), nil
 }
@@ -2,17 +2,16 @@ package qmilvus
 
 import (
 	"context"
-
-	"github.com/milvus-io/milvus-sdk-go/v2/client"
 )
 
 func (c *Collection[v]) Drop(ctx context.Context) (err error) {
-	var (
-		_client client.Client
-	)
-	if _client, err = c.NewGrpcClient(ctx); err != nil {
-		return err
-	}
-	defer _client.Close()
-	return _client.DropCollection(ctx, c.collectionName)
+	c.ResetIndexCache()
+	return c.do(ctx, &operation{name: "drop", idempotent: true}, func(ctx context.Context) error {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer _client.Close()
+		return wrapError(_client.DropCollection(ctx, c.collectionName))
+	})
 }
@@ -0,0 +1,121 @@
+package qmilvus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// WithEmbedder attaches the embedder filling vector fields tagged `milvus:"embed_from=TextField"`.
+// Upsert embeds the text of every model whose vector is empty, batchSize texts per Embed call (default 64)
+func (collection *Collection[v]) WithEmbedder(embedder Embedder, batchSize ...int) (ret *Collection[v]) {
+	collection.embedder = embedder
+	collection.embedBatchSize = 64
+	if len(batchSize) > 0 && batchSize[0] > 0 {
+		collection.embedBatchSize = batchSize[0]
+	}
+	return collection
+}
+
+// fillEmbeddings sets the missing vectors of models from their embed_from text fields
+func (c *Collection[v]) fillEmbeddings(ctx context.Context, models []v) (err error) {
+	if c.embedder == nil || len(c.embedFrom) == 0 {
+		return nil
+	}
+	for vectorField, textField := range c.embedFrom {
+		var (
+			texts   []string
+			targets []reflect.Value
+			rows    []int
+			vector  = c.plan.fields[vectorField]
+			text    = c.plan.index(textField)
+		)
+		for i, row := range c.plan.rows(reflect.ValueOf(&models).Elem()) {
+			if !row.IsValid() {
+				// nil model, reported by Validate
+				continue
+			}
+			if target := vector.settable(row); target.Len() == 0 {
+				// a nil nested struct holding the text embeds the empty text
+				textValue := ""
+				if f, err := row.FieldByIndexErr(text); err == nil {
+					textValue = f.String()
+				}
+				texts = append(texts, textValue)
+				targets = append(targets, target)
+				rows = append(rows, i)
+			}
+		}
+		for start := 0; start < len(texts); start += c.embedBatchSize {
+			end := start + c.embedBatchSize
+			if end > len(texts) {
+				end = len(texts)
+			}
+			vectors, err := c.embedder.Embed(ctx, texts[start:end])
+			if err != nil {
+				return fmt.Errorf("embed %s from %s failed: %w", vectorField, textField, err)
+			}
+			if len(vectors) != end-start {
+				return fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), end-start)
+			}
+			for j, vector := range vectors {
+				if err = c.checkEmbeddingDim(vectorField, rows[start+j], vector); err != nil {
+					return err
+				}
+				targets[start+j].Set(reflect.ValueOf(vector))
+			}
+		}
+	}
+	return nil
+}
+
+// checkEmbeddingDim ensures the embedder produces vectors of the dim declared on the field
+func (c *Collection[v]) checkEmbeddingDim(vectorField string, row int, vector []float32) error {
+	for _, f := range c.schemaIn.Fields {
+		if f.Name != vectorField {
+			continue
+		}
+		if dim, err := strconv.Atoi(f.TypeParams[entity.TypeParamDim]); err == nil && dim != len(vector) {
+			return fmt.Errorf("embedder returned a vector of the wrong dim: %w", &ErrDimMismatch{Field: vectorField, Want: dim, Got: len(vector), Row: row})
+		}
+	}
+	return nil
+}
+
+// SearchText embeds text with the collection's Embedder and searches the vector field embedded from text
+func (c *Collection[v]) SearchText(ctx context.Context, text string, spa *SearchParams) (models []v, Scores []float32, err error) {
+	return c.SearchTextOn(ctx, c.vectorFieldName(), text, spa)
+}
+
+// SearchTextOn embeds text and searches vectorField, one of the vector fields tagged embed_from
+func (c *Collection[v]) SearchTextOn(ctx context.Context, vectorField string, text string, spa *SearchParams) (models []v, Scores []float32, err error) {
+	if c.embedder == nil {
+		return nil, nil, fmt.Errorf("SearchText of collection %s needs an embedder, see WithEmbedder", c.collectionName)
+	}
+	if _, ok := c.embedFrom[vectorField]; !ok {
+		return nil, nil, fmt.Errorf("vector field %s has no embed_from tag, it is not embedded from text", vectorField)
+	}
+	vectors, err := c.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, nil, fmt.Errorf("embed query failed: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, nil, fmt.Errorf("embedder returned %d vectors for 1 text", len(vectors))
+	}
+	if err = c.checkEmbeddingDim(vectorField, 0, vectors[0]); err != nil {
+		return nil, nil, err
+	}
+
+	vector, err := c.queryVector(vectorField, vectors[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	hits, scores, err := c.search(ctx, vectorField, []entity.Vector{vector}, spa)
+	if err != nil || len(hits) == 0 {
+		return nil, nil, err
+	}
+	return hits[0], scores[0], nil
+}
@@ -0,0 +1,220 @@
+package qmilvus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// exportBatchSize is the count of rows Export queries at once, importBatchSize the count Import upserts at once
+var (
+	exportBatchSize = 1000
+	importBatchSize = 1000
+)
+
+// Export writes the rows matching expr, all rows if expr is empty, to w in format, vectors included.
+// the rows are read in pages ordered by primary key, so the memory used is one page whatever the collection size.
+// n is the count of rows written, a tenant handle exports the rows of its tenant only
+func (c *Collection[v]) Export(ctx context.Context, w io.Writer, format Format, expr string) (n int, err error) {
+	resp, err := c.intercept(ctx, &Request[v]{Op: OpExport, Expression: expr}, func(ctx context.Context, req *Request[v]) (*Response[v], error) {
+		return c.handleExport(ctx, req, w, format)
+	})
+	if resp != nil {
+		n = int(resp.Count)
+	}
+	return n, err
+}
+
+// handleExport writes the rows matching req.Expression to w in format, resp.Count is the count of rows written, on errors too
+func (c *Collection[v]) handleExport(ctx context.Context, req *Request[v], w io.Writer, format Format) (resp *Response[v], err error) {
+	if c.pkFieldName == "" {
+		return nil, fmt.Errorf("collection %s has no primary key to page the export by: %w", c.collectionName, ErrInvalidArgument)
+	}
+	out, err := newRecordWriter(w, format, c.schemaIn.Fields)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.scopeRequest(req); err != nil {
+		return nil, err
+	}
+	fieldNames := make([]string, 0, len(c.schemaIn.Fields))
+	for _, f := range c.schemaIn.Fields {
+		fieldNames = append(fieldNames, f.Name)
+	}
+
+	resp = &Response[v]{}
+	err = c.pageRows(ctx, c.partitions(), req.Expression, fieldNames, func(resultSet client.ResultSet) error {
+		for i := 0; i < resultSet.Len(); i++ {
+			row := make(map[string]interface{}, len(resultSet))
+			for _, column := range resultSet {
+				if row[column.Name()], err = exportValue(column, i); err != nil {
+					return fmt.Errorf("export %s row %d: %w", column.Name(), resp.Count, err)
+				}
+			}
+			if err = out.Write(row); err != nil {
+				return err
+			}
+			resp.Count++
+		}
+		return nil
+	})
+	if err != nil {
+		return resp, err
+	}
+	return resp, out.Close()
+}
+
+// pageRows queries the fields of the rows of partitions matching expr, in pages of exportBatchSize rows ordered by primary key,
+// fn is called with every page
+func (c *Collection[v]) pageRows(ctx context.Context, partitions []string, expr string, fieldNames []string, fn func(client.ResultSet) error) (err error) {
+	page := expr
+	for {
+		var resultSet client.ResultSet
+		op := &operation{name: "export", idempotent: true}
+		err = c.do(ctx, op, func(ctx context.Context) error {
+			_client, err := c.getClient()
+			if err != nil {
+				return fmt.Errorf("get client failed: %w", err)
+			}
+			if err = _client.LoadCollection(ctx, c.collectionName, false); err != nil {
+				return wrapError(err)
+			}
+			opts := append(c.readOptions(ConsistencyDefault), client.WithLimit(int64(exportBatchSize)))
+			resultSet, err = _client.Query(ctx, c.collectionName, partitions, page, fieldNames, opts...)
+			op.returned = resultSet.Len()
+			return wrapError(err)
+		})
+		if err != nil {
+			return err
+		}
+		count := resultSet.Len()
+		if count > 0 {
+			if err = fn(resultSet); err != nil {
+				return err
+			}
+		}
+		if count < exportBatchSize {
+			return nil
+		}
+		// the next page starts after the last primary key of this one
+		last, err := resultSet.GetColumn(c.pkFieldName).Get(count - 1)
+		if err != nil {
+			return err
+		}
+		page = c.pkFieldName + " > " + pkLiteral(last)
+		if expr != "" {
+			page = "(" + expr + ") and " + page
+		}
+	}
+}
+
+// pkLiteral returns the expression literal of a primary key
+func pkLiteral(pk interface{}) string {
+	if s, ok := pk.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(pk)
+}
+
+// Import reads the rows of r in format, as written by Export, into models and upserts them in batches of importBatchSize.
+// every field of the file should be a field of the collection, and every field of the collection should be in every row.
+// parquet is read through ReadAt when r is an *os.File or *bytes.Reader, other readers are read to memory first.
+// n is the count of rows upserted. with ValidateDrop the invalid rows are left out, and reported by a *ValidationError
+// once the others are upserted, its rows counted from the first row of r
+func (c *Collection[v]) Import(ctx context.Context, r io.Reader, format Format) (n int, err error) {
+	in, err := newRecordReader(r, format, c.schemaIn.Fields)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		dropped *ValidationError
+		seen    int
+		batch   = make([]map[string]interface{}, 0, importBatchSize)
+	)
+	flush := func() error {
+		models, err := c.importModels(batch, seen)
+		if err != nil {
+			return err
+		}
+		written := len(models)
+		var verr *ValidationError
+		_, err = c.intercept(ctx, &Request[v]{Op: OpUpsert, Models: models}, c.handleWrite)
+		if errors.As(err, &verr) && verr.Dropped {
+			if dropped == nil {
+				dropped = &ValidationError{Dropped: true}
+			}
+			for _, violation := range verr.Violations {
+				violation.Row += seen
+				dropped.Violations = append(dropped.Violations, violation)
+			}
+			written, err = written-len(verr.invalidRows()), nil
+		}
+		if err != nil {
+			return err
+		}
+		n, seen, batch = n+written, seen+len(batch), batch[:0]
+		return nil
+	}
+	for {
+		row, err := in.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, fmt.Errorf("import row %d: %w", seen+len(batch), err)
+		}
+		if batch = append(batch, row); len(batch) == importBatchSize {
+			if err = flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	if len(batch) > 0 {
+		err = flush()
+	}
+	if dropped != nil {
+		return n, errors.Join(err, dropped)
+	}
+	return n, err
+}
+
+// importModels maps the rows read by Import to models, first is the row number of rows[0] in the file
+func (c *Collection[v]) importModels(rows []map[string]interface{}, first int) (models []v, err error) {
+	in := make(map[string]bool, len(c.schemaIn.Fields))
+	for _, field := range c.schemaIn.Fields {
+		in[field.Name] = true
+	}
+	// every row is checked on its own, whatever the other rows of the batch hold
+	for i, row := range rows {
+		for name := range row {
+			if !in[name] {
+				return nil, fmt.Errorf("import row %d: field %s is not a field of collection %s: %w", first+i, name, c.collectionName, ErrInvalidArgument)
+			}
+		}
+		for _, field := range c.schemaIn.Fields {
+			if x, ok := row[field.Name]; !ok || x == nil {
+				return nil, fmt.Errorf("import row %d lacks field %s, or its csv cell is empty: %w", first+i, field.Name, ErrInvalidArgument)
+			}
+		}
+	}
+	columns := make([]entity.Column, 0, len(c.schemaIn.Fields))
+	for _, field := range c.schemaIn.Fields {
+		values := make([]interface{}, len(rows))
+		for i, row := range rows {
+			if values[i], err = importValue(field, row[field.Name]); err != nil {
+				return nil, fmt.Errorf("import row %d field %s: %w", first+i, field.Name, err)
+			}
+		}
+		column, err := newColumn(field, values)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return c.parseColumns(len(rows), columns)
+}
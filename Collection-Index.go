@@ -0,0 +1,109 @@
+package qmilvus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// vectorFieldName returns the field searched by SearchVector:
+// IndexFieldName if set, otherwise the first float, float16 or bfloat16 vector field of the schema
+func (c *Collection[v]) vectorFieldName() string {
+	if c.IndexFieldName != "" {
+		return c.IndexFieldName
+	}
+	for _, f := range c.schemaIn.Fields {
+		if f.DataType == entity.FieldTypeFloatVector || f.DataType == entity.FieldTypeFloat16Vector || f.DataType == entity.FieldTypeBFloat16Vector {
+			return f.Name
+		}
+	}
+	return ""
+}
+
+// binaryFieldName returns the field searched by SearchBinary:
+// IndexFieldName if it is a binary vector, otherwise the first binary vector field of the schema
+func (c *Collection[v]) binaryFieldName() string {
+	if fp, ok := c.plan.fields[c.IndexFieldName]; ok && fp.field.DataType == entity.FieldTypeBinaryVector {
+		return c.IndexFieldName
+	}
+	for _, f := range c.schemaIn.Fields {
+		if f.DataType == entity.FieldTypeBinaryVector {
+			return f.Name
+		}
+	}
+	return ""
+}
+
+// mustVectorField panics if field is not a vector field of the schema
+func (c *Collection[v]) mustVectorField(field string) {
+	if fp, ok := c.plan.fields[field]; !ok || !isVector(fp.field.DataType) {
+		panic(fmt.Errorf("collection %s has no vector field %q", c.collectionName, field))
+	}
+}
+
+// fieldIndex returns the index Create builds on the vector field: the one set by WithFieldIndex,
+// Index on the field searched by SearchVector, otherwise DefaultIndex
+func (c *Collection[v]) fieldIndex(field *entity.Field) (entity.Index, error) {
+	if index, ok := c.indexes[field.Name]; ok {
+		return index, nil
+	}
+	if c.Index != nil && field.Name == c.vectorFieldName() {
+		return c.Index, nil
+	}
+	return DefaultIndex(field)
+}
+
+// DescribeIndex returns the index built on fieldName, as reported by milvus.
+// the result is cached per field, call ResetIndexCache after rebuilding the index outside this collection
+func (c *Collection[v]) DescribeIndex(ctx context.Context, fieldName string) (index entity.Index, err error) {
+	err = c.do(ctx, &operation{name: "describe_index", idempotent: true}, func(ctx context.Context) (err error) {
+		index, err = c.describeIndex(ctx, fieldName)
+		return err
+	})
+	return index, err
+}
+
+// describeIndex is DescribeIndex without retry, for operations already retried as a whole
+func (c *Collection[v]) describeIndex(ctx context.Context, fieldName string) (index entity.Index, err error) {
+	c.indexMu.Lock()
+	index, ok := c.indexCache[fieldName]
+	c.indexMu.Unlock()
+	if ok {
+		return index, nil
+	}
+
+	var _client client.Client
+	if _client, err = c.getClient(); err != nil {
+		return nil, fmt.Errorf("get client failed: %w", err)
+	}
+	indexes, err := _client.DescribeIndex(ctx, c.collectionName, fieldName)
+	if err != nil {
+		return nil, fmt.Errorf("describe index on field %s failed: %w", fieldName, wrapError(err))
+	}
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("no index built on field %s of collection %s: %w", fieldName, c.collectionName, ErrIndexNotFound)
+	}
+	index = indexes[0]
+
+	c.indexMu.Lock()
+	if c.indexCache == nil {
+		c.indexCache = map[string]entity.Index{}
+	}
+	c.indexCache[fieldName] = index
+	c.indexMu.Unlock()
+	return index, nil
+}
+
+// ResetIndexCache forgets all indexes cached by DescribeIndex
+func (c *Collection[v]) ResetIndexCache() {
+	c.indexMu.Lock()
+	c.indexCache = nil
+	c.indexMu.Unlock()
+}
+
+// indexMetricType returns the metric type the index was built with, empty if unknown
+func indexMetricType(index entity.Index) entity.MetricType {
+	return entity.MetricType(index.Params()["metric_type"])
+}
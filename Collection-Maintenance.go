@@ -0,0 +1,179 @@
+package qmilvus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// compactionPollInterval is the interval Compact polls the compaction state
+var compactionPollInterval = 500 * time.Millisecond
+
+// Flush seals the growing segments and waits until they are persisted.
+// milvus flushes by itself every few seconds, call Flush only when a batch must be durable before going on
+func (c *Collection[v]) Flush(ctx context.Context) (err error) {
+	return c.do(ctx, &operation{name: "flush", idempotent: true}, c.flush)
+}
+
+func (c *Collection[v]) flush(ctx context.Context) (err error) {
+	var (
+		_client client.Client
+	)
+	if _client, err = c.NewGrpcClient(ctx); err != nil {
+		return err
+	}
+	defer _client.Close()
+	return wrapError(_client.Flush(ctx, c.collectionName, false))
+}
+
+// Compact merges small segments and purges deleted rows, it returns when the compaction is completed.
+// the compaction is triggered once, only the polling of its state is retried
+func (c *Collection[v]) Compact(ctx context.Context) (err error) {
+	var compactionID int64
+	err = c.do(ctx, &operation{name: "compact"}, func(ctx context.Context) (err error) {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer _client.Close()
+		compactionID, err = _client.ManualCompaction(ctx, c.collectionName, 0)
+		return wrapError(err)
+	})
+	if err != nil {
+		return err
+	}
+	return c.do(ctx, &operation{name: "compaction state", idempotent: true}, func(ctx context.Context) error {
+		return c.waitCompaction(ctx, compactionID)
+	})
+}
+
+// waitCompaction polls the state of the compaction until it is completed
+func (c *Collection[v]) waitCompaction(ctx context.Context, compactionID int64) (err error) {
+	_client, err := c.NewGrpcClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer _client.Close()
+	grpcClient, ok := _client.(*client.GrpcClient)
+	if !ok {
+		return fmt.Errorf("compaction state needs a grpc client, got %T", _client)
+	}
+
+	ticker := time.NewTicker(compactionPollInterval)
+	defer ticker.Stop()
+	for {
+		resp, err := grpcClient.Service.GetCompactionState(ctx, &milvuspb.GetCompactionStateRequest{CompactionID: compactionID})
+		if err != nil {
+			return wrapError(err)
+		}
+		if done, err := compactionDone(compactionID, resp); done || err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("compaction %d of collection %s not completed: %w", compactionID, c.collectionName, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// compactionDone reports whether the compaction is completed, and fails it when some of its plans failed or timed out
+func compactionDone(compactionID int64, resp *milvuspb.GetCompactionStateResponse) (done bool, err error) {
+	if entity.CompactionState(resp.GetState()) != entity.CompactionStateCompleted {
+		return false, nil
+	}
+	if resp.GetFailedPlanNo() > 0 || resp.GetTimeoutPlanNo() > 0 {
+		return true, fmt.Errorf("compaction %d: %d of %d plans failed, %d timed out", compactionID,
+			resp.GetFailedPlanNo(), resp.GetCompletedPlanNo()+resp.GetFailedPlanNo()+resp.GetTimeoutPlanNo(), resp.GetTimeoutPlanNo())
+	}
+	return true, nil
+}
+
+// SegmentReport summarizes the segments of the collection
+type SegmentReport struct {
+	Segments []*entity.Segment
+
+	Growing, Sealed, Flushed int   // segment count by state, Flushing counted as Sealed
+	GrowingRows, SealedRows  int64 // SealedRows includes flushed rows
+}
+
+// Segments reports the segments of the collection with their row counts and states.
+// growing segments are reported by the query nodes, so only while the collection is loaded
+func (c *Collection[v]) Segments(ctx context.Context) (report *SegmentReport, err error) {
+	err = c.do(ctx, &operation{name: "segments", idempotent: true}, func(ctx context.Context) (err error) {
+		report, err = c.segments(ctx)
+		return err
+	})
+	return report, err
+}
+
+func (c *Collection[v]) segments(ctx context.Context) (report *SegmentReport, err error) {
+	var (
+		_client            client.Client
+		persistent, loaded []*entity.Segment
+	)
+	if _client, err = c.NewGrpcClient(ctx); err != nil {
+		return nil, err
+	}
+	defer _client.Close()
+	if persistent, err = _client.GetPersistentSegmentInfo(ctx, c.collectionName); err != nil {
+		return nil, wrapError(err)
+	}
+	if loaded, err = querySegments(ctx, _client, c.collectionName); err != nil && !errors.Is(err, ErrNotLoaded) {
+		return nil, err
+	}
+	return newSegmentReport(persistent, loaded), nil
+}
+
+// querySegments returns the segments loaded in the query nodes, growing ones included.
+// GetQuerySegmentInfo of the sdk drops the state of the segments, the service is called directly
+func querySegments(ctx context.Context, c client.Client, collection string) ([]*entity.Segment, error) {
+	grpcClient, ok := c.(*client.GrpcClient)
+	if !ok {
+		return nil, fmt.Errorf("query segment info needs a grpc client, got %T", c)
+	}
+	resp, err := grpcClient.Service.GetQuerySegmentInfo(ctx, &milvuspb.GetQuerySegmentInfoRequest{CollectionName: collection})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	segments := make([]*entity.Segment, 0, len(resp.GetInfos()))
+	for _, info := range resp.GetInfos() {
+		segments = append(segments, &entity.Segment{ID: info.GetSegmentID(), CollectionID: info.GetCollectionID(), ParititionID: info.GetPartitionID(),
+			IndexID: info.GetIndexID(), NumRows: info.GetNumRows(), State: info.GetState()})
+	}
+	return segments, nil
+}
+
+// newSegmentReport counts the persistent segments, and the loaded ones not persisted yet, by state
+func newSegmentReport(persistent, loaded []*entity.Segment) *SegmentReport {
+	report := &SegmentReport{Segments: persistent}
+	seen := make(map[int64]bool, len(persistent))
+	for _, s := range persistent {
+		seen[s.ID] = true
+	}
+	for _, s := range loaded {
+		if !seen[s.ID] {
+			report.Segments = append(report.Segments, s)
+		}
+	}
+	for _, s := range report.Segments {
+		switch s.State {
+		case commonpb.SegmentState_Growing:
+			report.Growing++
+			report.GrowingRows += s.NumRows
+		case commonpb.SegmentState_Sealed, commonpb.SegmentState_Flushing:
+			report.Sealed++
+			report.SealedRows += s.NumRows
+		case commonpb.SegmentState_Flushed:
+			report.Flushed++
+			report.SealedRows += s.NumRows
+		}
+	}
+	return report
+}
@@ -0,0 +1,45 @@
+package qmilvus
+
+import (
+	"testing"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-proto/go-api/v2/milvuspb"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+func TestSegmentReport(t *testing.T) {
+	persistent := []*entity.Segment{
+		{ID: 1, NumRows: 100, State: commonpb.SegmentState_Flushed},
+		{ID: 2, NumRows: 50, State: commonpb.SegmentState_Sealed},
+		{ID: 3, NumRows: 20, State: commonpb.SegmentState_Flushing},
+	}
+	// the query nodes report the loaded segments again, and the growing ones
+	loaded := []*entity.Segment{
+		{ID: 1, NumRows: 100, State: commonpb.SegmentState_Sealed},
+		{ID: 4, NumRows: 7, State: commonpb.SegmentState_Growing},
+	}
+	report := newSegmentReport(persistent, loaded)
+	if len(report.Segments) != 4 || report.Growing != 1 || report.Sealed != 2 || report.Flushed != 1 || report.GrowingRows != 7 || report.SealedRows != 170 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if report = newSegmentReport(persistent, nil); report.Growing != 0 || report.SealedRows != 170 {
+		t.Errorf("unexpected report of an unloaded collection %+v", report)
+	}
+}
+
+func TestCompactionDone(t *testing.T) {
+	for _, tc := range []struct {
+		resp       *milvuspb.GetCompactionStateResponse
+		done, fail bool
+	}{
+		{&milvuspb.GetCompactionStateResponse{State: commonpb.CompactionState_Executing, ExecutingPlanNo: 2}, false, false},
+		{&milvuspb.GetCompactionStateResponse{State: commonpb.CompactionState_Completed, CompletedPlanNo: 2}, true, false},
+		{&milvuspb.GetCompactionStateResponse{State: commonpb.CompactionState_Completed, CompletedPlanNo: 1, FailedPlanNo: 1}, true, true},
+		{&milvuspb.GetCompactionStateResponse{State: commonpb.CompactionState_Completed, TimeoutPlanNo: 1}, true, true},
+	} {
+		if done, err := compactionDone(7, tc.resp); done != tc.done || (err != nil) != tc.fail {
+			t.Errorf("%v: got %v, %v", tc.resp, done, err)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package qmilvus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// ListPartitions returns the partitions of the collection, with their load state
+func (c *Collection[v]) ListPartitions(ctx context.Context) (partitions []*entity.Partition, err error) {
+	err = c.do(ctx, &operation{name: "list partitions", idempotent: true}, func(ctx context.Context) error {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer _client.Close()
+		partitions, err = _client.ShowPartitions(ctx, c.collectionName)
+		return wrapError(err)
+	})
+	return partitions, err
+}
+
+// HasPartition reports whether the collection has the partition name
+func (c *Collection[v]) HasPartition(ctx context.Context, name string) (has bool, err error) {
+	err = c.do(ctx, &operation{name: "has partition", idempotent: true}, func(ctx context.Context) error {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer _client.Close()
+		has, err = _client.HasPartition(ctx, c.collectionName, name)
+		return wrapError(err)
+	})
+	return has, err
+}
+
+// CreatePartition creates the partition name, a partition already existing fails with ErrAlreadyExists.
+// collections partitioned by key have their partitions managed by milvus
+func (c *Collection[v]) CreatePartition(ctx context.Context, name string) (err error) {
+	if c.hasPartitionKey() {
+		return fmt.Errorf("collection %s is partitioned by key, milvus manages its partitions: %w", c.collectionName, ErrInvalidArgument)
+	}
+	return c.do(ctx, &operation{name: "create partition", idempotent: true}, func(ctx context.Context) error {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer _client.Close()
+		return wrapError(_client.CreatePartition(ctx, c.collectionName, name))
+	})
+}
+
+// DropPartition releases the partition name and drops it with its rows
+func (c *Collection[v]) DropPartition(ctx context.Context, name string) (err error) {
+	if c.hasPartitionKey() {
+		return fmt.Errorf("collection %s is partitioned by key, milvus manages its partitions: %w", c.collectionName, ErrInvalidArgument)
+	}
+	err = c.do(ctx, &operation{name: "drop partition", idempotent: true}, func(ctx context.Context) error {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer _client.Close()
+		// milvus drops released partitions only
+		if err = wrapError(_client.ReleasePartitions(ctx, c.collectionName, []string{name})); err != nil && !errors.Is(err, ErrNotLoaded) {
+			return err
+		}
+		return wrapError(_client.DropPartition(ctx, c.collectionName, name))
+	})
+	if err == nil {
+		c.partitionMu.Lock()
+		delete(c.partitionCreated, name)
+		c.partitionMu.Unlock()
+	}
+	return err
+}
+
+// LoadPartitions loads the partitions into memory and waits until they are loaded, the others may stay released
+func (c *Collection[v]) LoadPartitions(ctx context.Context, names ...string) (err error) {
+	return c.do(ctx, &operation{name: "load partitions", idempotent: true}, func(ctx context.Context) error {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer _client.Close()
+		return wrapError(_client.LoadPartitions(ctx, c.collectionName, names, false))
+	})
+}
+
+// ReleasePartitions releases the partitions from memory, searches and queries of the collection then skip them
+func (c *Collection[v]) ReleasePartitions(ctx context.Context, names ...string) (err error) {
+	return c.do(ctx, &operation{name: "release partitions", idempotent: true}, func(ctx context.Context) error {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer _client.Close()
+		return wrapError(_client.ReleasePartitions(ctx, c.collectionName, names))
+	})
+}
+
+// WithPartitioner writes every model to the partition named by partitioner, created on the first write to it,
+// the _default partition if the name is empty. see PartitionByMonth.
+// the partition name of the collection is cleared: searches, queries and removes span all the partitions,
+// set one with WithPartitionName on a handle to restrict them. an upsert moving a model to another partition
+// leaves its previous row in place, remove it first
+func (c *Collection[v]) WithPartitioner(partitioner func(model v) string) (ret *Collection[v]) {
+	if c.hasPartitionKey() {
+		panic(fmt.Errorf("collection %s is partitioned by key, milvus manages its partitions", c.collectionName))
+	}
+	c.partitioner = partitioner
+	c.partitionName = ""
+	return c
+}
+
+// PartitionByTime returns a partitioner naming partitions prefix followed by the time of the model formatted by layout,
+// i.g. PartitionByTime("events_", "2006_01_02", func(e *Event) time.Time { return e.At }) for daily partitions.
+// partition names are letters, digits and underscores, the times are taken in UTC
+func PartitionByTime[v any](prefix, layout string, at func(model v) time.Time) func(model v) string {
+	return func(model v) string {
+		return prefix + at(model).UTC().Format(layout)
+	}
+}
+
+// PartitionByMonth returns a partitioner naming partitions by the month of the model, i.g. events_2024_05
+func PartitionByMonth[v any](prefix string, at func(model v) time.Time) func(model v) string {
+	return PartitionByTime(prefix, "2006_01", at)
+}
+
+// writePartitioned validates the models and writes them to the partitions named by the partitioner
+func (c *Collection[v]) writePartitioned(ctx context.Context, opType string, models []v) (err error) {
+	valid, dropped, err := c.prepareModels(ctx, models)
+	if err != nil {
+		return err
+	}
+	names, groups, err := c.partitionGroups(valid)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err = c.ensurePartition(ctx, name); err != nil {
+			return err
+		}
+		columes, err := c.BuildColumns(groups[name]...)
+		if err != nil {
+			return err
+		}
+		if err = c.write(ctx, opType, name, columes); err != nil {
+			return fmt.Errorf("partition %s: %w", name, err)
+		}
+	}
+	if dropped != nil {
+		return dropped
+	}
+	return nil
+}
+
+// partitionGroups groups the models by the partition named by the partitioner, names in the order of their first model
+func (c *Collection[v]) partitionGroups(models []v) (names []string, groups map[string][]v, err error) {
+	rows := c.plan.rows(reflect.ValueOf(&models).Elem())
+	groups = map[string][]v{}
+	for i, model := range models {
+		if !rows[i].IsValid() {
+			return nil, nil, fmt.Errorf("row %d: %w: %w", i, errNilModel, ErrInvalidArgument)
+		}
+		name := c.partitioner(model)
+		if name == "" {
+			name = "_default"
+		}
+		if _, ok := groups[name]; !ok {
+			names = append(names, name)
+		}
+		groups[name] = append(groups[name], model)
+	}
+	return names, groups, nil
+}
+
+// ensurePartition creates the partition name unless this collection created or saw it already
+func (c *Collection[v]) ensurePartition(ctx context.Context, name string) (err error) {
+	c.partitionMu.Lock()
+	created := c.partitionCreated[name]
+	c.partitionMu.Unlock()
+	if created || name == "_default" {
+		return nil
+	}
+	err = c.do(ctx, &operation{name: "create partition", idempotent: true}, func(ctx context.Context) error {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		defer _client.Close()
+		if err = wrapError(_client.CreatePartition(ctx, c.collectionName, name)); err != nil && !errors.Is(err, ErrAlreadyExists) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("create partition %s: %w", name, err)
+	}
+	c.partitionMu.Lock()
+	if c.partitionCreated == nil {
+		c.partitionCreated = map[string]bool{}
+	}
+	c.partitionCreated[name] = true
+	c.partitionMu.Unlock()
+	return nil
+}
@@ -0,0 +1,48 @@
+package qmilvus
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type Event struct {
+	Id     int64     `milvus:"in,out,PK"`
+	At     time.Time `milvus:"in,out"`
+	Vector []float32 `milvus:"in,dim=4"`
+}
+
+func TestPartitioner(t *testing.T) {
+	c := NewCollection[*Event](milvusAdress).WithPartitioner(PartitionByMonth("events_", func(e *Event) time.Time { return e.At }))
+	if c.partitionName != "" || c.partitions() != nil {
+		t.Errorf("a partitioned collection should search all partitions, got %q", c.partitionName)
+	}
+	may := time.Date(2024, 5, 31, 23, 0, 0, 0, time.UTC)
+	events := []*Event{{Id: 1, At: may}, {Id: 2, At: may.AddDate(0, 1, 0)}, {Id: 3, At: may.Add(-time.Hour)}}
+	names, groups, err := c.partitionGroups(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"events_2024_05", "events_2024_07"}) {
+		t.Errorf("partitions %v", names)
+	}
+	if g := groups["events_2024_05"]; len(g) != 2 || g[0].Id != 1 || g[1].Id != 3 {
+		t.Errorf("events of may %v", g)
+	}
+	// times are taken in UTC
+	east := time.FixedZone("UTC+8", 8*3600)
+	if name := PartitionByTime("d", "2006_01_02", func(e *Event) time.Time { return e.At })(&Event{At: time.Date(2024, 6, 1, 2, 0, 0, 0, east)}); name != "d2024_05_31" {
+		t.Errorf("daily partition %s", name)
+	}
+	if _, _, err = c.partitionGroups([]*Event{events[0], nil}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("nil models should be rejected, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("collections partitioned by key should refuse a partitioner")
+		}
+	}()
+	NewCollection[*KeyedDoc](milvusAdress).WithPartitioner(func(*KeyedDoc) string { return "p" })
+}
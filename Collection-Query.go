@@ -0,0 +1,97 @@
+package qmilvus
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+)
+
+// QueryParams controls a scalar query
+type QueryParams struct {
+	Expression  string
+	Limit       int64
+	Offset      int64
+	Consistency Consistency // ConsistencyDefault uses the level of the collection
+}
+
+func (q *QueryParams) WithExpression(expr string) *QueryParams {
+	ret := *q
+	ret.Expression = expr
+	return &ret
+}
+func (q *QueryParams) WithLimit(limit int64) *QueryParams {
+	ret := *q
+	ret.Limit = limit
+	return &ret
+}
+func (q *QueryParams) WithOffset(offset int64) *QueryParams {
+	ret := *q
+	ret.Offset = offset
+	return &ret
+}
+func (q *QueryParams) WithConsistency(cl Consistency) *QueryParams {
+	ret := *q
+	ret.Consistency = cl
+	return &ret
+}
+
+var QueryParamsDefault = &QueryParams{
+	Expression: "",
+	Limit:      100,
+}
+
+// Query returns the models matching the boolean expression qp.Expression, e.g. `Id in [1,2,3]`
+func (c *Collection[v]) Query(qp *QueryParams) (models []v, err error) {
+	if qp == nil {
+		qp = QueryParamsDefault
+	}
+	resp, err := c.intercept(c.ctx, &Request[v]{Op: OpQuery, Query: qp}, c.handleQuery)
+	if err != nil || resp == nil || len(resp.Models) == 0 {
+		return nil, err
+	}
+	return resp.Models[0], nil
+}
+
+func (c *Collection[v]) handleQuery(ctx context.Context, req *Request[v]) (resp *Response[v], err error) {
+	if err = c.scopeRequest(req); err != nil {
+		return nil, err
+	}
+	var (
+		resultSet client.ResultSet
+		qp        = req.Query
+	)
+	if qp == nil {
+		qp = QueryParamsDefault
+	}
+
+	op := &operation{name: "query", idempotent: true}
+	err = c.do(ctx, op, func(ctx context.Context) error {
+		_client, err := c.getClient()
+		if err != nil {
+			return fmt.Errorf("get client failed: %w", err)
+		}
+		opts := c.readOptions(qp.Consistency)
+		if qp.Limit > 0 {
+			opts = append(opts, client.WithLimit(qp.Limit))
+		}
+		if qp.Offset > 0 {
+			opts = append(opts, client.WithOffset(qp.Offset))
+		}
+		//LoadCollection is necessary
+		if err = _client.LoadCollection(ctx, c.collectionName, false); err != nil {
+			return wrapError(err)
+		}
+		resultSet, err = _client.Query(ctx, c.collectionName, c.partitions(), qp.Expression, c.outputFields, opts...)
+		op.returned = resultSet.Len()
+		return wrapError(err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	models, err := c.parseColumns(resultSet.Len(), resultSet)
+	if err != nil {
+		return nil, err
+	}
+	return &Response[v]{Models: [][]v{models}}, nil
+}
@@ -1,51 +1,71 @@
 package qmilvus
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/milvus-io/milvus-sdk-go/v2/client"
 	"github.com/milvus-io/milvus-sdk-go/v2/entity"
 )
 
+// SearchParams controls a search request.
+// SearchParam and MetricType are derived from the index built on the vector field when left empty,
+// only the knobs NProbe, Ef and SearchK need to be set to tune the derived search param
 type SearchParams struct {
 	SearchParam entity.SearchParam
 	MetricType  entity.MetricType
 	Expression  string
 	TopK        int
+
+	NProbe  int // IVF_* / SCANN / BIN_IVF_FLAT, default 10
+	Ef      int // HNSW / IVF_HNSW ef, DISKANN search_list, default max(64, TopK)
+	SearchK int // ANNOY search_k, default -1
+
+	Consistency Consistency // ConsistencyDefault uses the level of the collection
 }
 
 func (s *SearchParams) WithSearchParam(sp entity.SearchParam) *SearchParams {
-	return &SearchParams{
-		SearchParam: sp,
-		MetricType:  s.MetricType,
-		Expression:  s.Expression,
-		TopK:        s.TopK,
-	}
+	ret := *s
+	ret.SearchParam = sp
+	return &ret
 }
 func (s *SearchParams) WithMetricType(mt entity.MetricType) *SearchParams {
-	return &SearchParams{
-		SearchParam: s.SearchParam,
-		MetricType:  mt,
-		Expression:  s.Expression,
-		TopK:        s.TopK,
-	}
+	ret := *s
+	ret.MetricType = mt
+	return &ret
 }
 func (s *SearchParams) WithExpression(expr string) *SearchParams {
-	return &SearchParams{
-		SearchParam: s.SearchParam,
-		MetricType:  s.MetricType,
-		Expression:  expr,
-		TopK:        s.TopK,
-	}
+	ret := *s
+	ret.Expression = expr
+	return &ret
 }
 func (s *SearchParams) WithTopK(topk int) *SearchParams {
-	return &SearchParams{
-		SearchParam: s.SearchParam,
-		MetricType:  s.MetricType,
-		Expression:  s.Expression,
-		TopK:        topk,
-	}
+	ret := *s
+	ret.TopK = topk
+	return &ret
+}
+func (s *SearchParams) WithNProbe(nprobe int) *SearchParams {
+	ret := *s
+	ret.NProbe = nprobe
+	return &ret
+}
+func (s *SearchParams) WithEf(ef int) *SearchParams {
+	ret := *s
+	ret.Ef = ef
+	return &ret
+}
+func (s *SearchParams) WithSearchK(searchK int) *SearchParams {
+	ret := *s
+	ret.SearchK = searchK
+	return &ret
+}
+func (s *SearchParams) WithConsistency(cl Consistency) *SearchParams {
+	ret := *s
+	ret.Consistency = cl
+	return &ret
 }
 
 func SearchParamIndexFlat() entity.SearchParam {
@@ -58,351 +78,316 @@ func SearchParamIVFFlat(nprobe int) entity.SearchParam {
 	searchParam, _ := entity.NewIndexIvfFlatSearchParam(nprobe)
 	return searchParam
 }
-func SearchParamHNSW(nprobe int) entity.SearchParam {
+func SearchParamHNSW(ef int) entity.SearchParam {
 	// Use HNSW search param
-	searchParam, _ := entity.NewIndexHNSWSearchParam(nprobe)
+	searchParam, _ := entity.NewIndexHNSWSearchParam(ef)
 	return searchParam
 }
-func SearchParamANNOY(nprobe, ef int) entity.SearchParam {
-	// Use ANNOY search param
+func SearchParamIVFHNSW(nprobe, ef int) entity.SearchParam {
+	// Use IVF_HNSW search param
 	searchParam, _ := entity.NewIndexIvfHNSWSearchParam(nprobe, ef)
 	return searchParam
 }
+func SearchParamANNOY(searchK int) entity.SearchParam {
+	// Use ANNOY search param, the sdk ships none since milvus 2.3 dropped ANNOY
+	return &annoySearchParam{params: map[string]interface{}{"search_k": searchK}}
+}
+
+type annoySearchParam struct {
+	params map[string]interface{}
+}
+
+func (sp *annoySearchParam) Params() map[string]interface{} {
+	params := make(map[string]interface{}, len(sp.params))
+	for k, v := range sp.params {
+		params[k] = v
+	}
+	return params
+}
+func (sp *annoySearchParam) AddRadius(radius float64)           { sp.params["radius"] = radius }
+func (sp *annoySearchParam) AddRangeFilter(rangeFilter float64) { sp.params["range_filter"] = rangeFilter }
 
+// SearchParamsDefault leaves SearchParam and MetricType empty, so both are taken from the index
 var SearchParamsDefault = &SearchParams{
-	SearchParam: SearchParamIndexFlat(),
-	MetricType:  entity.COSINE,
-	Expression:  "",
-	TopK:        100,
+	Expression: "",
+	TopK:       100,
 }
 
-// / SearchVector searches for the most similar vectors in the collection
-// / @param query: the query vector
-// / @param spa: use qmilvus.SearchParamsDefault to set default values, including SearchParam, MetricType, Expression, TopK;
-// / @return models: the most similar vectors
-func (c *Collection[v]) SearchVector(query []float32, spa *SearchParams) (models []v, Scores []float32, err error) {
-	var (
-		results []client.SearchResult
-	)
+// searchParamForIndex builds the search param matching the index type, tuned by the knobs of spa
+func searchParamForIndex(index entity.Index, spa *SearchParams) (entity.SearchParam, error) {
+	nprobe := spa.NProbe
+	if nprobe <= 0 {
+		nprobe = 10
+	}
+	ef := spa.Ef
+	if ef <= 0 {
+		ef = 64
+	}
+	if ef < spa.TopK {
+		ef = spa.TopK
+	}
+	searchK := spa.SearchK
+	if searchK == 0 {
+		searchK = -1
+	}
 
-	client, err := c.getClient()
-	if err != nil {
-		return nil, nil, fmt.Errorf("get client failed: %w", err)
+	switch index.IndexType() {
+	case entity.Flat:
+		return entity.NewIndexFlatSearchParam()
+	case entity.BinFlat:
+		return entity.NewIndexBinFlatSearchParam(nprobe)
+	case entity.IvfFlat:
+		return entity.NewIndexIvfFlatSearchParam(nprobe)
+	case entity.BinIvfFlat:
+		return entity.NewIndexBinIvfFlatSearchParam(nprobe)
+	case entity.IvfSQ8:
+		return entity.NewIndexIvfSQ8SearchParam(nprobe)
+	case entity.IvfPQ:
+		return entity.NewIndexIvfPQSearchParam(nprobe)
+	case entity.HNSW:
+		return entity.NewIndexHNSWSearchParam(ef)
+	case entity.IvfHNSW:
+		return entity.NewIndexIvfHNSWSearchParam(nprobe, ef)
+	case entity.DISKANN:
+		return entity.NewIndexDISKANNSearchParam(ef)
+	case entity.SCANN:
+		return entity.NewIndexSCANNSearchParam(nprobe, spa.TopK)
+	case entity.AUTOINDEX:
+		return entity.NewIndexAUTOINDEXSearchParam(1)
+	case entity.GPUIvfFlat:
+		return entity.NewIndexGPUIvfFlatSearchParam(nprobe)
+	case entity.GPUIvfPQ:
+		return entity.NewIndexGPUIvfPQSearchParam(nprobe)
+	case entity.IndexType(IndexANNOY):
+		return SearchParamANNOY(searchK), nil
+	default:
+		// unknown index, let milvus apply its defaults
+		return entity.NewIndexFlatSearchParam()
 	}
+}
 
-	//查询最相近的相似度
-	vectors, vectorField := []entity.Vector{entity.FloatVector(query)}, c.IndexFieldName
-	//LoadCollection is necessary
-	err = client.LoadCollection(c.ctx, c.collectionName, false)
+// resolveSearchParams returns the search param and metric type for searching fieldName.
+// values set in spa win, but a MetricType that contradicts the index metric is rejected before reaching milvus
+func (c *Collection[v]) resolveSearchParams(ctx context.Context, fieldName string, spa *SearchParams) (sp entity.SearchParam, mt entity.MetricType, err error) {
+	sp, mt = spa.SearchParam, spa.MetricType
+	index, err := c.describeIndex(ctx, fieldName)
 	if err != nil {
-		return nil, nil, err
+		// a field without index cannot be searched, whatever the params
+		if sp != nil && mt != "" && !errors.Is(err, ErrIndexNotFound) {
+			// fully specified by caller, index info not needed
+			return sp, mt, nil
+		}
+		return nil, "", err
 	}
-	if results, err = client.Search(c.ctx, c.collectionName, []string{c.partitionName}, spa.Expression, c.outputFields, vectors, vectorField, spa.MetricType, spa.TopK, spa.SearchParam); err != nil {
-		return nil, nil, err
+
+	if indexMetric := indexMetricType(index); indexMetric != "" {
+		if mt == "" {
+			mt = indexMetric
+		} else if !strings.EqualFold(string(mt), string(indexMetric)) {
+			return nil, "", fmt.Errorf("metric type %s does not match metric %s of index on field %s: %w", mt, indexMetric, fieldName, ErrInvalidArgument)
+		}
 	}
+	if sp == nil {
+		if sp, err = searchParamForIndex(index, spa); err != nil {
+			return nil, "", fmt.Errorf("build search param for index %s on field %s failed: %w", index.IndexType(), fieldName, err)
+		}
+	}
+	return sp, mt, nil
+}
 
-	//get Two column Id and Score, and return
-	Scores = results[0].Scores
-	models, err = c.ParseSearchResult(&results[0])
-	return models, Scores, err
+// search runs one search request of the vectors against vectorField through the interceptors
+func (c *Collection[v]) search(ctx context.Context, vectorField string, vectors []entity.Vector, spa *SearchParams) (models [][]v, Scores [][]float32, err error) {
+	if spa == nil {
+		spa = SearchParamsDefault
+	}
+	resp, err := c.intercept(ctx, &Request[v]{Op: OpSearch, VectorField: vectorField, Vectors: vectors, Search: spa}, c.handleSearch)
+	if err != nil || resp == nil {
+		return nil, nil, err
+	}
+	return resp.Models, resp.Scores, nil
 }
 
-func (c *Collection[v]) SearchVectors(query [][]float32, spa *SearchParams) (models [][]v, Scores [][]float32, err error) {
+func (c *Collection[v]) handleSearch(ctx context.Context, req *Request[v]) (resp *Response[v], err error) {
+	if err = c.scopeRequest(req); err != nil {
+		return nil, err
+	}
 	var (
 		results []client.SearchResult
+		spa     = req.Search
 	)
+	if spa == nil {
+		spa = SearchParamsDefault
+	}
+
+	op := &operation{name: "search", idempotent: true, nq: len(req.Vectors), topK: spa.TopK}
+	err = c.do(ctx, op, func(ctx context.Context) error {
+		client, err := c.getClient()
+		if err != nil {
+			return fmt.Errorf("get client failed: %w", err)
+		}
 
-	client, err := c.getClient()
+		searchParam, metricType, err := c.resolveSearchParams(ctx, req.VectorField, spa)
+		if err != nil {
+			return err
+		}
+		//LoadCollection is necessary
+		if err = client.LoadCollection(ctx, c.collectionName, false); err != nil {
+			return wrapError(err)
+		}
+		results, err = client.Search(ctx, c.collectionName, c.partitions(), spa.Expression, c.outputFields, req.Vectors, req.VectorField, metricType, spa.TopK, searchParam, c.readOptions(spa.Consistency)...)
+		for _, result := range results {
+			op.returned += result.ResultCount
+		}
+		return wrapError(err)
+	})
 	if err != nil {
-		return nil, nil, fmt.Errorf("get client failed: %w", err)
+		return nil, err
 	}
 
-	//查询最相近的相似度
-	vectors, vectorField := []entity.Vector{}, c.IndexFieldName
-	for _, q := range query {
-		vectors = append(vectors, entity.FloatVector(q))
-	}
-	//LoadCollection is necessary
-	err = client.LoadCollection(c.ctx, c.collectionName, false)
-	if err != nil {
-		return nil, nil, err
+	resp = &Response[v]{}
+	for _, result := range results {
+		modelsi, err := c.ParseSearchResult(&result)
+		if err != nil {
+			return nil, err
+		}
+		resp.Models = append(resp.Models, modelsi)
+		resp.Scores = append(resp.Scores, result.Scores)
 	}
+	return resp, nil
+}
 
-	// Use flat search param
-	if results, err = client.Search(c.ctx, c.collectionName, []string{c.partitionName}, spa.Expression, c.outputFields, vectors, vectorField, spa.MetricType, spa.TopK, spa.SearchParam); err != nil {
+// / SearchVector searches for the most similar vectors in the collection
+// / @param query: the query vector
+// / @param spa: use qmilvus.SearchParamsDefault to set default values, including SearchParam, MetricType, Expression, TopK;
+// / SearchParam and MetricType are derived from the index when left empty
+// / @return models: the most similar vectors
+func (c *Collection[v]) SearchVector(query []float32, spa *SearchParams) (models []v, Scores []float32, err error) {
+	return c.SearchVectorOn(c.vectorFieldName(), query, spa)
+}
+
+func (c *Collection[v]) SearchVectors(query [][]float32, spa *SearchParams) (models [][]v, Scores [][]float32, err error) {
+	return c.SearchVectorsOn(c.vectorFieldName(), query, spa)
+}
+
+// SearchVectorOn searches the vector field vectorField, of a struct with several vectors,
+// i.g. SearchVectorOn("BodyVec", query, qmilvus.SearchParamsDefault)
+func (c *Collection[v]) SearchVectorOn(vectorField string, query []float32, spa *SearchParams) (models []v, Scores []float32, err error) {
+	hits, scores, err := c.SearchVectorsOn(vectorField, [][]float32{query}, spa)
+	if err != nil || len(hits) == 0 {
 		return nil, nil, err
 	}
+	return hits[0], scores[0], nil
+}
 
-	for _, result := range results {
-		modelsi, err := c.ParseSearchResult(&result)
+func (c *Collection[v]) SearchVectorsOn(vectorField string, query [][]float32, spa *SearchParams) (models [][]v, Scores [][]float32, err error) {
+	//查询最相近的相似度
+	vectors := []entity.Vector{}
+	for _, q := range query {
+		vector, err := c.queryVector(vectorField, q)
 		if err != nil {
 			return nil, nil, err
 		}
-		models = append(models, modelsi)
-		Scores = append(Scores, result.Scores)
+		vectors = append(vectors, vector)
+	}
+	return c.search(c.ctx, vectorField, vectors, spa)
+}
 
+// queryVector wraps the float32 query as a vector of the type of vectorField, converted for float16 and bfloat16 fields
+func (c *Collection[v]) queryVector(vectorField string, query []float32) (entity.Vector, error) {
+	fp, ok := c.plan.fields[vectorField]
+	if !ok || !isVector(fp.field.DataType) {
+		return nil, fmt.Errorf("collection %s has no float vector field %q: %w", c.collectionName, vectorField, ErrInvalidArgument)
+	}
+	switch fp.field.DataType {
+	case entity.FieldTypeFloat16Vector:
+		return entity.Float16Vector(Float32ToFloat16(query)), nil
+	case entity.FieldTypeBFloat16Vector:
+		return entity.BFloat16Vector(Float32ToBFloat16(query)), nil
+	case entity.FieldTypeBinaryVector:
+		return nil, fmt.Errorf("field %s is a binary vector, search it with SearchBinary: %w", vectorField, ErrInvalidArgument)
+	}
+	return entity.FloatVector(query), nil
+}
+
+// SearchBinary searches the binary vector field with query, dim/8 bytes,
+// by the HAMMING or JACCARD metric of its BIN_FLAT or BIN_IVF_FLAT index
+func (c *Collection[v]) SearchBinary(query []byte, spa *SearchParams) (models []v, Scores []float32, err error) {
+	return c.SearchBinaryOn(c.binaryFieldName(), query, spa)
+}
+
+func (c *Collection[v]) SearchBinaries(query [][]byte, spa *SearchParams) (models [][]v, Scores [][]float32, err error) {
+	return c.SearchBinariesOn(c.binaryFieldName(), query, spa)
+}
+
+// SearchBinaryOn searches the binary vector field vectorField with query
+func (c *Collection[v]) SearchBinaryOn(vectorField string, query []byte, spa *SearchParams) (models []v, Scores []float32, err error) {
+	hits, scores, err := c.SearchBinariesOn(vectorField, [][]byte{query}, spa)
+	if err != nil || len(hits) == 0 {
+		return nil, nil, err
 	}
-	return models, Scores, err
+	return hits[0], scores[0], nil
+}
+
+func (c *Collection[v]) SearchBinariesOn(vectorField string, query [][]byte, spa *SearchParams) (models [][]v, Scores [][]float32, err error) {
+	fp, ok := c.plan.fields[vectorField]
+	if !ok || fp.field.DataType != entity.FieldTypeBinaryVector {
+		return nil, nil, fmt.Errorf("collection %s has no binary vector field %q: %w", c.collectionName, vectorField, ErrInvalidArgument)
+	}
+	vectors := make([]entity.Vector, len(query))
+	for i, q := range query {
+		if len(q) != fp.dim/8 {
+			return nil, nil, &ErrDimMismatch{Field: vectorField, Want: fp.dim / 8, Got: len(q), Row: i}
+		}
+		vectors[i] = entity.BinaryVector(q)
+	}
+	return c.search(c.ctx, vectorField, vectors, spa)
 }
 
 func (c *Collection[v]) ParseSearchResult(result *client.SearchResult) (models []v, err error) {
-	resultCount := result.ResultCount
+	return c.parseColumns(result.ResultCount, result.Fields)
+}
+
+// parseColumns builds resultCount models of type v, filled from the returned columns
+func (c *Collection[v]) parseColumns(resultCount int, fields []entity.Column) (models []v, err error) {
 	if resultCount == 0 {
 		return []v{}, nil
 	}
+	if c.codec != nil {
+		return c.codec.Models(resultCount, fields)
+	}
 
+	// the structs of pointer models share one backing array
 	models = make([]v, resultCount)
-
-	vType := reflect.TypeOf((*v)(nil)).Elem()
-	if vType.Kind() != reflect.Ptr {
-		return nil, fmt.Errorf("generic type 'v' must be a pointer type (e.g., *MyStruct), but got %s", vType.Kind())
-	}
-	elemType := vType.Elem()
-	for i := 0; i < result.ResultCount; i++ {
-		models[i] = reflect.New(elemType).Interface().(v)
-	}
+	rows := c.plan.newModels(reflect.ValueOf(&models).Elem())
 
 	// 填充其他在 outputFields 中请求的字段
-	for _, field := range result.Fields {
-		err = c.SetModelFields(field, models)
-		if err != nil {
-			// 如果某个字段设置失败，可以选择记录日志并继续，或者直接返回错误
-			// fmt.Printf("Warning: failed to set field '%s': %v\n", field.Name(), err)
-			// return nil, err // 如果希望一个字段失败就整体失败
-			return nil, fmt.Errorf("failed to set field '%s': %w", field.Name(), err) // 更推荐的方式
+	for _, field := range fields {
+		if err = c.setColumn(field, rows); err != nil {
+			return nil, fmt.Errorf("failed to set field '%s': %w", field.Name(), err)
 		}
 	}
-
 	return models, nil
-
 }
 
 // SetModelFields 将单个 Column 的数据设置到 models 切片对应的实例字段中
-// v 必须是指针类型，例如 *MyStruct
 func (c *Collection[v]) SetModelFields(column entity.Column, models []v) error {
-	columnName := column.Name()
-	columnLen := column.Len()
+	return c.setColumn(column, c.plan.rows(reflect.ValueOf(&models).Elem()))
+}
 
+// setColumn sets the field of column in every row, columns of fields the model lacks are ignored
+func (c *Collection[v]) setColumn(column entity.Column, rows []reflect.Value) error {
 	// 确保列长度和模型数量匹配
-	if columnLen != len(models) && len(models) > 0 { // 如果 models 为空则跳过
-		return fmt.Errorf("column '%s' length (%d) does not match model count (%d)", columnName, columnLen, len(models))
+	if column.Len() != len(rows) && len(rows) > 0 {
+		return fmt.Errorf("column '%s' length (%d) does not match model count (%d)", column.Name(), column.Len(), len(rows))
 	}
-	if len(models) == 0 {
-		return nil // 没有模型需要填充
+	fp, ok := c.plan.fields[column.Name()]
+	if !ok || len(rows) == 0 {
+		return nil
 	}
-
-	// 根据列类型处理
-	switch source := column.(type) {
-	case *entity.ColumnInt64:
-		data := source.Data()
-		for i, val := range data {
-			modelVal := reflect.ValueOf(models[i]).Elem() // models[i] 是 *MyStruct, Elem() 获取 MyStruct
-			fieldVal := modelVal.FieldByName(columnName)
-			if !fieldVal.IsValid() {
-				// 字段不存在，可以选择忽略或返回错误
-				// fmt.Printf("Warning: field '%s' not found in model type %s\n", columnName, modelVal.Type())
-				continue // 忽略
-				// return fmt.Errorf("field '%s' not found in model type %s", columnName, modelVal.Type())
-			}
-			if !fieldVal.CanSet() {
-				// 字段不可设置（可能是未导出的字段）
-				return fmt.Errorf("field '%s' in model type %s cannot be set", columnName, modelVal.Type())
-			}
-			// 检查类型是否匹配或可转换
-			if fieldVal.Kind() != reflect.Int64 {
-				// 可选：尝试类型转换，例如如果字段是 int
-				if fieldVal.Kind() == reflect.Int {
-					fieldVal.SetInt(int64(val)) // 显式转换
-					continue
-				}
-				return fmt.Errorf("type mismatch for field '%s': expected %s, got Int64 from Milvus", columnName, fieldVal.Kind())
-			}
-			fieldVal.SetInt(val)
-		}
-	case *entity.ColumnVarChar:
-		data := source.Data()
-		for i, val := range data {
-			modelVal := reflect.ValueOf(models[i]).Elem()
-			fieldVal := modelVal.FieldByName(columnName)
-			if !fieldVal.IsValid() {
-				continue
-			} // 忽略未找到的字段
-			if !fieldVal.CanSet() {
-				return fmt.Errorf("field '%s' cannot be set", columnName)
-			}
-			if fieldVal.Kind() != reflect.String {
-				return fmt.Errorf("type mismatch for field '%s': expected %s, got VarChar/String from Milvus", columnName, fieldVal.Kind())
-			}
-			fieldVal.SetString(val)
-		}
-	case *entity.ColumnString: // String 和 VarChar 类似
-		data := source.Data()
-		for i, val := range data {
-			modelVal := reflect.ValueOf(models[i]).Elem()
-			fieldVal := modelVal.FieldByName(columnName)
-			if !fieldVal.IsValid() {
-				continue
-			} // 忽略未找到的字段
-			if !fieldVal.CanSet() {
-				return fmt.Errorf("field '%s' cannot be set", columnName)
-			}
-			if fieldVal.Kind() != reflect.String {
-				return fmt.Errorf("type mismatch for field '%s': expected %s, got String from Milvus", columnName, fieldVal.Kind())
-			}
-			fieldVal.SetString(val)
-		}
-	case *entity.ColumnFloat:
-		data := source.Data()
-		for i, val := range data {
-			modelVal := reflect.ValueOf(models[i]).Elem()
-			fieldVal := modelVal.FieldByName(columnName)
-			if !fieldVal.IsValid() {
-				continue
-			} // 忽略未找到的字段
-			if !fieldVal.CanSet() {
-				return fmt.Errorf("field '%s' cannot be set", columnName)
-			}
-			if fieldVal.Kind() != reflect.Float32 {
-				// 可选：如果字段是 float64，进行转换
-				if fieldVal.Kind() == reflect.Float64 {
-					fieldVal.SetFloat(float64(val))
-					continue
-				}
-				return fmt.Errorf("type mismatch for field '%s': expected %s, got Float32 from Milvus", columnName, fieldVal.Kind())
-			}
-			fieldVal.Set(reflect.ValueOf(val)) // 使用 Set() for float32
-		}
-	case *entity.ColumnDouble:
-		data := source.Data()
-		for i, val := range data {
-			modelVal := reflect.ValueOf(models[i]).Elem()
-			fieldVal := modelVal.FieldByName(columnName)
-			if !fieldVal.IsValid() {
-				continue
-			} // 忽略未找到的字段
-			if !fieldVal.CanSet() {
-				return fmt.Errorf("field '%s' cannot be set", columnName)
-			}
-			if fieldVal.Kind() != reflect.Float64 {
-				return fmt.Errorf("type mismatch for field '%s': expected %s, got Float64 from Milvus", columnName, fieldVal.Kind())
-			}
-			fieldVal.SetFloat(val)
-		}
-	case *entity.ColumnBool:
-		data := source.Data()
-		for i, val := range data {
-			modelVal := reflect.ValueOf(models[i]).Elem()
-			fieldVal := modelVal.FieldByName(columnName)
-			if !fieldVal.IsValid() {
-				continue
-			} // 忽略未找到的字段
-			if !fieldVal.CanSet() {
-				return fmt.Errorf("field '%s' cannot be set", columnName)
-			}
-			if fieldVal.Kind() != reflect.Bool {
-				return fmt.Errorf("type mismatch for field '%s': expected %s, got Bool from Milvus", columnName, fieldVal.Kind())
-			}
-			fieldVal.SetBool(val)
-		}
-	case *entity.ColumnInt8:
-		data := source.Data()
-		for i, val := range data {
-			modelVal := reflect.ValueOf(models[i]).Elem()
-			fieldVal := modelVal.FieldByName(columnName)
-			if !fieldVal.IsValid() {
-				continue
-			} // 忽略未找到的字段
-			if !fieldVal.CanSet() {
-				return fmt.Errorf("field '%s' cannot be set", columnName)
-			}
-			if fieldVal.Kind() != reflect.Int8 {
-				return fmt.Errorf("type mismatch for field '%s': expected %s, got Int8 from Milvus", columnName, fieldVal.Kind())
-			}
-			fieldVal.Set(reflect.ValueOf(val))
+	for i, row := range rows {
+		if !row.IsValid() {
+			return fmt.Errorf("row %d: %w", i, errNilModel)
 		}
-	case *entity.ColumnInt16:
-		data := source.Data()
-		for i, val := range data {
-			modelVal := reflect.ValueOf(models[i]).Elem()
-			fieldVal := modelVal.FieldByName(columnName)
-			if !fieldVal.IsValid() {
-				continue
-			} // 忽略未找到的字段
-			if !fieldVal.CanSet() {
-				return fmt.Errorf("field '%s' cannot be set", columnName)
-			}
-			if fieldVal.Kind() != reflect.Int16 {
-				return fmt.Errorf("type mismatch for field '%s': expected %s, got Int16 from Milvus", columnName, fieldVal.Kind())
-			}
-			fieldVal.Set(reflect.ValueOf(val))
-		}
-	case *entity.ColumnInt32:
-		data := source.Data()
-		for i, val := range data {
-			modelVal := reflect.ValueOf(models[i]).Elem()
-			fieldVal := modelVal.FieldByName(columnName)
-			if !fieldVal.IsValid() {
-				continue
-			} // 忽略未找到的字段
-			if !fieldVal.CanSet() {
-				return fmt.Errorf("field '%s' cannot be set", columnName)
-			}
-			if fieldVal.Kind() != reflect.Int32 {
-				// 可选：如果字段是 int，进行转换
-				if fieldVal.Kind() == reflect.Int {
-					fieldVal.SetInt(int64(val)) // SetInt takes int64
-					continue
-				}
-				return fmt.Errorf("type mismatch for field '%s': expected %s, got Int32 from Milvus", columnName, fieldVal.Kind())
-			}
-			fieldVal.Set(reflect.ValueOf(val))
-		}
-	case *entity.ColumnBinaryVector:
-		data := source.Data()
-		for i, val := range data {
-			modelVal := reflect.ValueOf(models[i]).Elem()
-			fieldVal := modelVal.FieldByName(columnName)
-			if !fieldVal.IsValid() {
-				continue
-			} // 忽略未找到的字段
-			if !fieldVal.CanSet() {
-				return fmt.Errorf("field '%s' cannot be set", columnName)
-			}
-			// 假设 Go 结构体字段是 []byte
-			if fieldVal.Kind() != reflect.Slice || fieldVal.Type().Elem().Kind() != reflect.Uint8 {
-				return fmt.Errorf("type mismatch for field '%s': expected []byte, got BinaryVector from Milvus", columnName)
-			}
-			fieldVal.SetBytes(val)
-		}
-	case *entity.ColumnFloatVector: // 添加对 FloatVector 的处理 (如果需要)
-		data := source.Data()
-		for i, val := range data {
-			modelVal := reflect.ValueOf(models[i]).Elem()
-			fieldVal := modelVal.FieldByName(columnName)
-			if !fieldVal.IsValid() {
-				continue
-			} // 忽略未找到的字段
-			if !fieldVal.CanSet() {
-				return fmt.Errorf("field '%s' cannot be set", columnName)
-			}
-			// 假设 Go 结构体字段是 []float32
-			if fieldVal.Kind() != reflect.Slice || fieldVal.Type().Elem().Kind() != reflect.Float32 {
-				return fmt.Errorf("type mismatch for field '%s': expected []float32, got FloatVector from Milvus", columnName)
-			}
-			fieldVal.Set(reflect.ValueOf(val)) // 设置整个切片
-		}
-	// 添加对其他 Milvus 类型的处理，例如 JSON
-	// case *entity.ColumnJSON:
-	//     data := source.Data() // data is [][]byte
-	//     for i, val := range data {
-	//         // ... 根据 Go 结构体字段类型 (如 map[string]interface{}, struct, or []byte) 进行 unmarshal
-	//     }
-
-	default:
-		// 对于未明确处理的类型，可以选择忽略或返回错误
-		// fmt.Printf("Warning: unsupported column type %T for column '%s'\n", column, columnName)
-		return fmt.Errorf("unsupported column type %T for column '%s'", column, columnName)
 	}
-
-	return nil // 没有错误
+	return fp.set(rows, column)
 }
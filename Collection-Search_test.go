@@ -0,0 +1,167 @@
+package qmilvus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+func TestResolveSearchParamsFromIndex(t *testing.T) {
+	c := NewCollection[*OggAction](milvusAdress)
+	c.indexCache = map[string]entity.Index{
+		"Vector": entity.NewGenericIndex("Vector", entity.HNSW, map[string]string{"index_type": "HNSW", "metric_type": "IP"}),
+	}
+
+	sp, mt, err := c.resolveSearchParams(context.Background(), "Vector", SearchParamsDefault.WithTopK(200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt != entity.IP {
+		t.Errorf("metric type = %s, want IP", mt)
+	}
+	if ef := sp.Params()["ef"]; ef != 200 {
+		t.Errorf("ef = %v, want 200 (raised to TopK)", ef)
+	}
+
+	if _, _, err = c.resolveSearchParams(context.Background(), "Vector", SearchParamsDefault.WithMetricType(entity.L2)); err == nil {
+		t.Error("expected error on metric contradicting the index metric")
+	}
+	if c.WithCollectionName("OggActionsCopy"); c.indexCache != nil {
+		t.Error("the indexes of the previous collection should be forgotten")
+	}
+}
+
+func TestSearchParamForIndexKnobs(t *testing.T) {
+	index := entity.NewGenericIndex("Vector", entity.IvfFlat, map[string]string{"metric_type": "L2"})
+	sp, err := searchParamForIndex(index, SearchParamsDefault.WithNProbe(32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nprobe := sp.Params()["nprobe"]; nprobe != 32 {
+		t.Errorf("nprobe = %v, want 32", nprobe)
+	}
+	if sk := SearchParamANNOY(50).Params()["search_k"]; sk != 50 {
+		t.Errorf("search_k = %v, want 50", sk)
+	}
+}
+
+type HashedImage struct {
+	Id   int64  `milvus:"in,out,PK"`
+	Hash []byte `milvus:"in,out,dim=64"`
+}
+
+func TestBinaryVectors(t *testing.T) {
+	c := NewCollection[*HashedImage](milvusAdress)
+	if f := c.schemaIn.Fields[1]; f.DataType != entity.FieldTypeBinaryVector || f.TypeParams[entity.TypeParamDim] != "64" {
+		t.Fatalf("unexpected field %+v", f)
+	}
+	in := []*HashedImage{{Id: 1, Hash: []byte{1, 2, 3, 4, 5, 6, 7, 8}}}
+	columns, err := c.BuildColumns(in...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := c.ParseSearchResult(&client.SearchResult{ResultCount: 1, Fields: columns})
+	if err != nil || !reflect.DeepEqual(out, in) {
+		t.Fatalf("models differ after a round trip: %v", err)
+	}
+
+	var dimErr *ErrDimMismatch
+	if _, _, err = c.SearchBinary([]byte{1, 2}, nil); !errors.As(err, &dimErr) || dimErr.Want != 8 {
+		t.Errorf("expected a dim mismatch of the 2 bytes query, got %v", err)
+	}
+	if _, _, err = c.SearchVector([]float32{1}, nil); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("SearchVector of a collection without float vectors should fail, got %v", err)
+	}
+	if c.vectorFieldName() != "" || c.binaryFieldName() != "Hash" {
+		t.Errorf("Hash should be searched by SearchBinary only")
+	}
+
+	index, err := IndexBinIvfFlat.NewIndex(entity.HAMMING, 128)
+	if err != nil || index.IndexType() != entity.BinIvfFlat {
+		t.Fatalf("unexpected index %v, %v", index, err)
+	}
+	if _, err = IndexBinFlat.NewIndex(entity.L2, 128); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("L2 should not fit a binary index")
+	}
+	c.indexCache = map[string]entity.Index{"Hash": entity.NewGenericIndex("Hash", entity.BinIvfFlat, index.Params())}
+	sp, mt, err := c.resolveSearchParams(context.Background(), "Hash", SearchParamsDefault.WithNProbe(16))
+	if err != nil || mt != entity.HAMMING || sp.Params()["nprobe"] != 16 {
+		t.Errorf("unexpected search params %v %s, %v", sp, mt, err)
+	}
+
+	if _, err = SchemaField(StructField{Name: "Hash", Type: "[]uint8", Tag: `milvus:"in,dim=12"`}); err == nil {
+		t.Errorf("dim=12 is not a whole number of bytes")
+	}
+}
+
+type Article struct {
+	Id       int64     `milvus:"in,out,pk"`
+	Title    string    `milvus:"in,out,max_length=256"`
+	TitleVec []float32 `milvus:"in,dim=4"`
+	BodyVec  []float32 `milvus:"in,dim=8,vector=float16"`
+	Hash     []byte    `milvus:"in,dim=16"`
+}
+
+type HashedArticle struct {
+	Id       int64     `milvus:"in,out,pk"`
+	Hash     []byte    `milvus:"in,dim=16"`
+	TitleVec []float32 `milvus:"in,dim=4"`
+}
+
+func TestMultipleVectors(t *testing.T) {
+	hnsw, _ := entity.NewIndexHNSW(entity.COSINE, 16, 200)
+	c := NewCollection[*Article](milvusAdress).WithFieldIndex("BodyVec", hnsw)
+	if c.vectorFieldName() != "TitleVec" {
+		t.Errorf("TitleVec, the first vector, should be searched by default")
+	}
+	// every vector field is indexed, by AUTOINDEX or BIN_FLAT unless set
+	want := map[string]entity.IndexType{"TitleVec": entity.AUTOINDEX, "BodyVec": entity.HNSW, "Hash": entity.BinFlat}
+	for _, f := range c.schemaIn.Fields {
+		if !isVector(f.DataType) {
+			continue
+		}
+		if index, err := c.fieldIndex(f); err != nil || index.IndexType() != want[f.Name] {
+			t.Errorf("index of %s: %v, %v, want %s", f.Name, index, err, want[f.Name])
+		}
+	}
+
+	// Index goes to the float vector searched by default, even after a binary vector
+	hashed := NewCollection[*HashedArticle](milvusAdress).WithCreateIndex(hnsw)
+	want = map[string]entity.IndexType{"Hash": entity.BinFlat, "TitleVec": entity.HNSW}
+	for _, f := range hashed.schemaIn.Fields {
+		if !isVector(f.DataType) {
+			continue
+		}
+		if index, err := hashed.fieldIndex(f); err != nil || index.IndexType() != want[f.Name] {
+			t.Errorf("index of %s: %v, %v, want %s", f.Name, index, err, want[f.Name])
+		}
+	}
+
+	c.WithDefaultVectorField("BodyVec")
+	if c.vectorFieldName() != "BodyVec" {
+		t.Errorf("BodyVec should be searched by default")
+	}
+	if q, err := c.queryVector("BodyVec", make([]float32, 8)); err != nil || q.FieldType() != entity.FieldTypeFloat16Vector {
+		t.Errorf("queries of BodyVec should be float16, got %v", err)
+	}
+	for _, field := range []string{"Title", "Nope", "Hash"} {
+		if _, _, err := c.SearchVectorOn(field, []float32{1}, nil); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("SearchVectorOn(%q) should fail, got %v", field, err)
+		}
+	}
+	if _, _, err := c.SearchBinaryOn("TitleVec", []byte{1, 2}, nil); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("SearchBinaryOn a float vector should fail, got %v", err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Title is not a vector field, WithDefaultVectorField should panic")
+			}
+		}()
+		c.WithDefaultVectorField("Title")
+	}()
+}
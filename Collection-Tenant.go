@@ -0,0 +1,105 @@
+package qmilvus
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// tenantScope restricts a handle to the rows of one tenant
+type tenantScope struct {
+	value reflect.Value // the tenant id, of the type of the tenant field
+	expr  string        // Tenant == id
+}
+
+// ForTenant returns a handle of the collection restricted to the rows of tenant id, for collections storing many tenants.
+// the tenant field is the field tagged `milvus:"in,out,tenant"`, or else the one tagged `milvus:"in,out,partition_key"`.
+// the handle sets the tenant field of every written model, rejects models of another tenant,
+// and ANDs `Tenant == id` into the expression of every search, query and remove, including expressions set by interceptors.
+// the handle shares the connection, the index cache and the session of the collection. ForTenant panics on a tenant handle
+func (c *Collection[v]) ForTenant(id interface{}) (handle *Collection[v]) {
+	if c.tenant != nil {
+		panic(fmt.Errorf("collection %s is already a handle of tenant %v, call ForTenant on the collection", c.collectionName, c.tenant.value.Interface()))
+	}
+	if c.tenantField == "" {
+		panic(fmt.Errorf("collection %s has no field tagged tenant or partition_key", c.collectionName))
+	}
+	fieldType := c.plan.fields[c.tenantField].goType
+	value := reflect.ValueOf(id)
+	fits := value.IsValid() && value.CanConvert(fieldType)
+	if fits && fieldType.Kind() == reflect.String {
+		fits = value.Kind() == reflect.String
+	} else if fits {
+		fits = value.CanInt() || value.CanUint()
+	}
+	if !fits {
+		panic(fmt.Errorf("tenant id %v of type %T does not fit tenant field %s of type %s", id, id, c.tenantField, fieldType))
+	}
+	value = value.Convert(fieldType)
+	if value.IsZero() {
+		panic(fmt.Errorf("tenant id of collection %s should not be empty", c.collectionName))
+	}
+
+	var literal string
+	if value.Kind() == reflect.String {
+		literal = strconv.Quote(value.String())
+	} else {
+		literal = strconv.FormatInt(value.Int(), 10)
+	}
+	clone := *c
+	handle = &clone
+	// the handle has its own interceptor slice, Use on it leaves the collection unchanged
+	handle.interceptors = append([]Interceptor[v]{}, c.interceptors...)
+	handle.tenant = &tenantScope{value: value, expr: c.tenantField + " == " + literal}
+	return handle
+}
+
+// scopeExpression ANDs the tenant filter into expr
+func (t *tenantScope) scopeExpression(expr string) string {
+	if expr == "" {
+		return t.expr
+	}
+	return t.expr + " and (" + expr + ")"
+}
+
+// scopeRequest restricts req to the tenant of the handle, it runs after the interceptors so nothing escapes it.
+// the search and query params are copied, the caller's params are left unchanged
+func (c *Collection[v]) scopeRequest(req *Request[v]) (err error) {
+	t := c.tenant
+	if t == nil {
+		return nil
+	}
+	switch req.Op {
+	case OpUpsert, OpInsert:
+		tenant := c.plan.fields[c.tenantField]
+		for row, _v := range c.plan.rows(reflect.ValueOf(&req.Models).Elem()) {
+			if !_v.IsValid() {
+				// nil model, reported by Validate
+				continue
+			}
+			field := tenant.settable(_v)
+			if field.IsZero() {
+				field.Set(t.value)
+			} else if !field.Equal(t.value) {
+				return fmt.Errorf("row %d belongs to tenant %v, not to tenant %v of the handle: %w", row, field.Interface(), t.value.Interface(), ErrInvalidArgument)
+			}
+		}
+	case OpRemove, OpCount, OpExport:
+		req.Expression = t.scopeExpression(req.Expression)
+	case OpSearch:
+		spa := *SearchParamsDefault
+		if req.Search != nil {
+			spa = *req.Search
+		}
+		spa.Expression = t.scopeExpression(spa.Expression)
+		req.Search = &spa
+	case OpQuery:
+		qp := *QueryParamsDefault
+		if req.Query != nil {
+			qp = *req.Query
+		}
+		qp.Expression = t.scopeExpression(qp.Expression)
+		req.Query = &qp
+	}
+	return nil
+}
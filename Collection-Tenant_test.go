@@ -0,0 +1,81 @@
+package qmilvus
+
+import (
+	"errors"
+	"testing"
+)
+
+type TenantDoc struct {
+	Id     int64     `milvus:"in,out,PK"`
+	Tenant string    `milvus:"in,out,tenant,max_length=64"`
+	Vector []float32 `milvus:"in,dim=4"`
+}
+
+type KeyedDoc struct {
+	Id     int64     `milvus:"in,out,PK"`
+	Org    int64     `milvus:"in,out,partition_key"`
+	Vector []float32 `milvus:"in,dim=4"`
+}
+
+func TestTenantScope(t *testing.T) {
+	c := NewCollection[*TenantDoc](milvusAdress)
+	acme := c.ForTenant("acme")
+
+	docs := []*TenantDoc{{Id: 1}, {Id: 2, Tenant: "acme"}}
+	if err := acme.scopeRequest(&Request[*TenantDoc]{Op: OpUpsert, Models: docs}); err != nil || docs[0].Tenant != "acme" {
+		t.Fatalf("expected the tenant to be set, got %q, %v", docs[0].Tenant, err)
+	}
+	err := acme.scopeRequest(&Request[*TenantDoc]{Op: OpUpsert, Models: []*TenantDoc{{Id: 3, Tenant: "globex"}}})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("a model of another tenant should be rejected, got %v", err)
+	}
+
+	spa := SearchParamsDefault.WithExpression(`Id > 1 or Id < 0`)
+	req := &Request[*TenantDoc]{Op: OpSearch, Search: spa}
+	if acme.scopeRequest(req); req.Search.Expression != `Tenant == "acme" and (Id > 1 or Id < 0)` {
+		t.Errorf("unexpected search expression %s", req.Search.Expression)
+	}
+	if spa.Expression != `Id > 1 or Id < 0` {
+		t.Errorf("the caller's params should be left unchanged, got %s", spa.Expression)
+	}
+	req = &Request[*TenantDoc]{Op: OpQuery}
+	if acme.scopeRequest(req); req.Query.Expression != `Tenant == "acme"` || req.Query.Limit != QueryParamsDefault.Limit {
+		t.Errorf("unexpected query %+v", req.Query)
+	}
+	// the collection itself is not scoped
+	req = &Request[*TenantDoc]{Op: OpRemove, Expression: "Id == 1"}
+	if c.scopeRequest(req); req.Expression != "Id == 1" {
+		t.Errorf("unexpected remove expression %s", req.Expression)
+	}
+}
+
+func TestTenantPartitionKey(t *testing.T) {
+	c := NewCollection[*KeyedDoc](milvusAdress)
+	if c.partitionName != "" || c.partitions() != nil {
+		t.Errorf("collections partitioned by key should not name partitions, got %q", c.partitionName)
+	}
+	if !c.schemaIn.Fields[1].IsPartitionKey {
+		t.Errorf("Org should be the partition key")
+	}
+	req := &Request[*KeyedDoc]{Op: OpRemove, Expression: "Id in [1,2]"}
+	if c.ForTenant(42).scopeRequest(req); req.Expression != "Org == 42 and (Id in [1,2])" {
+		t.Errorf("unexpected remove expression %s", req.Expression)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("a string tenant id should not fit an int64 tenant field")
+		}
+	}()
+	c.ForTenant("42")
+}
+
+func TestForTenantOfTenantHandle(t *testing.T) {
+	acme := NewCollection[*TenantDoc](milvusAdress).ForTenant("acme")
+	defer func() {
+		if recover() == nil {
+			t.Errorf("ForTenant on a tenant handle should panic")
+		}
+	}()
+	acme.ForTenant("globex")
+}
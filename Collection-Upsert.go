@@ -4,93 +4,128 @@ import (
 	"context"
 	"fmt"
 	"reflect"
-	"strconv"
 
-	"github.com/milvus-io/milvus-sdk-go/v2/client"
 	"github.com/milvus-io/milvus-sdk-go/v2/entity"
 )
 
+// prepareWrite fills embedded vectors, validates the models and builds their columns.
+// when every model was dropped by validation, columes is nil and err is the *ValidationError
+func (c *Collection[v]) prepareWrite(ctx context.Context, models []v) (columes []entity.Column, dropped *ValidationError, err error) {
+	if models, dropped, err = c.prepareModels(ctx, models); err != nil {
+		return nil, nil, err
+	}
+	if columes, err = c.BuildColumns(models...); err != nil {
+		return nil, nil, err
+	}
+	return columes, dropped, nil
+}
+
+// prepareModels fills embedded vectors and validates the models, returning those to write
+func (c *Collection[v]) prepareModels(ctx context.Context, models []v) (valid []v, dropped *ValidationError, err error) {
+	// fill vectors embedded from text fields
+	if err = c.fillEmbeddings(ctx, models); err != nil {
+		return nil, nil, err
+	}
+	if valid, dropped, err = c.validateForUpsert(models); err != nil {
+		return nil, nil, err
+	}
+	if len(valid) == 0 && dropped != nil {
+		return nil, nil, dropped
+	}
+	return valid, dropped, nil
+}
+
 //检查源字段和目标字段的对应关系
 //parameter structSlice may be new data or old data
 
+// models are validated first, see WithValidation
 func (c *Collection[v]) Upsert(models ...v) (err error) {
-	var (
-		_client client.Client
-	)
-	// insert into default partition
-	if _client, err = c.NewGrpcClient(c.ctx); err != nil {
-		return err
-	}
-	// in a main func, remember to close the client
-	defer _client.Close()
-	columes := c.BuildColumns(models...)
-	if _, err = _client.Upsert(context.Background(), c.collectionName, c.partitionName, columes...); err != nil {
-		return err
-	}
+	_, err = c.intercept(c.ctx, &Request[v]{Op: OpUpsert, Models: models}, c.handleWrite)
 	return err
 	//no need to Flush，milvus auto Flush every second,if Flush too frequently, it will create too many file segment
+	//call Flush when a batch must be durable before signalling downstream
 }
 
-// columes is used to insert []struct to collection
-// the milvus Insert method accept collection only
-func (c *Collection[v]) BuildColumns(models ...v) (result []entity.Column) {
-	var (
-		colume entity.Column
-		err    error
-		dim    int = 0
-	)
-	//all fields of type v to columes
-	result = []entity.Column{}
-	for _, s := range c.schemaIn.Fields {
-		if s.DataType == entity.FieldTypeDouble {
-			colume = entity.NewColumnDouble(s.Name, []float64{})
-		} else if s.DataType == entity.FieldTypeFloat {
-			colume = entity.NewColumnFloat(s.Name, []float32{})
-		} else if s.DataType == entity.FieldTypeInt64 {
-			colume = entity.NewColumnInt64(s.Name, []int64{})
-		} else if s.DataType == entity.FieldTypeVarChar || s.DataType == entity.FieldTypeString {
-			colume = entity.NewColumnVarChar(s.Name, []string{})
-			//colume = entity.NewColumnString(s.Name, []string{})
-		} else if s.DataType == entity.FieldTypeFloatVector {
-			if dim, err = strconv.Atoi(s.TypeParams["dim"]); err != nil {
-				panic(err)
-			}
-			colume = entity.NewColumnFloatVector(s.Name, dim, [][]float32{})
-		} else if s.DataType == entity.FieldTypeInt32 {
-			colume = entity.NewColumnInt32(s.Name, []int32{})
-		} else if s.DataType == entity.FieldTypeInt16 {
-			colume = entity.NewColumnInt16(s.Name, []int16{})
-		} else if s.DataType == entity.FieldTypeInt8 {
-			colume = entity.NewColumnInt8(s.Name, []int8{})
-		} else if s.DataType == entity.FieldTypeBool {
-			colume = entity.NewColumnBool(s.Name, []bool{})
-		} else if s.DataType == entity.FieldTypeBinaryVector {
-			if dim, err = strconv.Atoi(s.TypeParams["dim"]); err != nil {
-				panic(err)
-			}
-			colume = entity.NewColumnBinaryVector(s.Name, dim, [][]byte{})
+// Insert writes models as new rows, an existing primary key gets a duplicate row.
+// unlike Upsert, Insert is never retried: a retry after a lost response would write the rows twice
+func (c *Collection[v]) Insert(models ...v) (err error) {
+	_, err = c.intercept(c.ctx, &Request[v]{Op: OpInsert, Models: models}, c.handleWrite)
+	return err
+}
+
+// handleWrite upserts or inserts req.Models, by req.Op
+func (c *Collection[v]) handleWrite(ctx context.Context, req *Request[v]) (resp *Response[v], err error) {
+	if err = c.scopeRequest(req); err != nil {
+		return nil, err
+	}
+	if c.partitioner != nil {
+		return nil, c.writePartitioned(ctx, req.Op, req.Models)
+	}
+	columes, dropped, err := c.prepareWrite(ctx, req.Models)
+	if err != nil || columes == nil {
+		return nil, err
+	}
+	if err = c.write(ctx, req.Op, c.partitionName, columes); err != nil {
+		return nil, err
+	}
+	if dropped != nil {
+		return nil, dropped
+	}
+	return nil, nil
+}
+
+// write upserts or inserts the columns into partition
+func (c *Collection[v]) write(ctx context.Context, opType string, partition string, columes []entity.Column) error {
+	// upsert is idempotent, safe to retry
+	op := &operation{name: "upsert", idempotent: true, rows: columnsLen(columes)}
+	if opType == OpInsert {
+		op = &operation{name: "insert", rows: columnsLen(columes)}
+	}
+	return c.do(ctx, op, func(ctx context.Context) error {
+		_client, err := c.NewGrpcClient(ctx)
+		if err != nil {
+			return err
+		}
+		// in a main func, remember to close the client
+		defer _client.Close()
+		if opType == OpInsert {
+			_, err = _client.Insert(ctx, c.collectionName, partition, columes...)
 		} else {
-			panic(fmt.Sprintf("unsupported data type: %v", s.DataType))
+			_, err = _client.Upsert(ctx, c.collectionName, partition, columes...)
 		}
+		return wrapError(err)
+	})
+}
 
-		for i := 0; i < len(models); i++ {
-			_v := reflect.ValueOf(models[i])
-			// check if _v is a pointer, if so, get the value
-			for _v.Kind() == reflect.Ptr {
-				_v = _v.Elem()
-			}
-			_field := _v.FieldByName(s.Name)
-			// check demension match, if not, skip Insert
-			if _field.Type().Kind() == reflect.Slice {
-				vectorLen := _field.Len()
-				if vectorLen != dim {
-					println("Error: milvus insert dim not match")
-				}
-			}
-			colume.AppendValue(_field.Interface())
+// columes is used to insert []struct to collection
+// the milvus Insert method accept collection only
+// a vector of the wrong length fails with *ErrDimMismatch
+func (c *Collection[v]) BuildColumns(models ...v) (result []entity.Column, err error) {
+	if c.codec != nil {
+		return c.codec.Columns(models)
+	}
+	rows := c.plan.rows(reflect.ValueOf(&models).Elem())
+	for i, row := range rows {
+		if !row.IsValid() {
+			return nil, fmt.Errorf("row %d: %w: %w", i, errNilModel, ErrInvalidArgument)
+		}
+	}
+	//all fields of type v to columes
+	result = make([]entity.Column, 0, len(c.plan.in))
+	for _, fp := range c.plan.in {
+		colume, err := fp.build(rows)
+		if err != nil {
+			return nil, err
 		}
-
 		result = append(result, colume)
 	}
-	return result
+	return result, nil
+}
+
+// columnsLen returns the row count of columns built by BuildColumns
+func columnsLen(columns []entity.Column) int {
+	if len(columns) == 0 {
+		return 0
+	}
+	return columns[0].Len()
 }
@@ -0,0 +1,213 @@
+package qmilvus
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// ValidationMode decides what Upsert does with rows failing Validate
+type ValidationMode int
+
+const (
+	// ValidateReject fails the whole Upsert when any row is invalid, the default
+	ValidateReject ValidationMode = iota
+	// ValidateDrop upserts the valid rows only, then returns the *ValidationError listing the dropped rows
+	ValidateDrop
+	// ValidateOff skips validation, rows go to milvus as they are
+	ValidateOff
+)
+
+// WithValidation sets how Upsert handles invalid rows
+func (collection *Collection[v]) WithValidation(mode ValidationMode) (ret *Collection[v]) {
+	collection.validationMode = mode
+	return collection
+}
+
+// Violation is one problem found in one row
+type Violation struct {
+	Row   int         // index of the model in the validated batch
+	PK    interface{} // primary key of the model, nil if the model is nil
+	Field string      // empty when the whole model is invalid
+	Err   error
+}
+
+func (v Violation) Error() string {
+	if v.Field == "" {
+		return fmt.Sprintf("row %d pk %v: %v", v.Row, v.PK, v.Err)
+	}
+	return fmt.Sprintf("row %d pk %v field %s: %v", v.Row, v.PK, v.Field, v.Err)
+}
+
+// ValidationError lists every violation found by Validate
+type ValidationError struct {
+	Violations []Violation
+	Dropped    bool // the invalid rows were dropped and the others upserted, see ValidateDrop
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Error()
+	}
+	if e.Dropped {
+		return fmt.Sprintf("%d invalid rows dropped: %s", len(e.invalidRows()), strings.Join(msgs, "; "))
+	}
+	return fmt.Sprintf("%d violations: %s", len(e.Violations), strings.Join(msgs, "; "))
+}
+
+// Is makes a validation error an ErrInvalidArgument
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidArgument
+}
+
+// Unwrap exposes the cause of every violation, e.g. *ErrDimMismatch, to errors.As
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Violations))
+	for i, v := range e.Violations {
+		errs[i] = v.Err
+	}
+	return errs
+}
+
+// invalidRows returns the set of rows having at least one violation
+func (e *ValidationError) invalidRows() map[int]bool {
+	rows := map[int]bool{}
+	for _, v := range e.Violations {
+		rows[v.Row] = true
+	}
+	return rows
+}
+
+var (
+	errNilModel   = errors.New("model is nil")
+	errNaN        = errors.New("value is NaN or Inf")
+	errZeroVector = errors.New("vector has zero norm, its cosine similarity is undefined")
+	errDuplicate  = errors.New("duplicate primary key in batch")
+)
+
+// Validate checks models before they are written: nil models, vector dims, VarChar max_length in bytes,
+// NaN/Inf floats, zero norm float vectors of fields indexed with the COSINE metric and primary keys duplicated in the batch.
+// it returns a *ValidationError listing every violation, or nil
+func (c *Collection[v]) Validate(models ...v) error {
+	var (
+		violations []Violation
+		pks        = map[interface{}]int{}
+		// zero vectors are rejected where the index Create builds compares by COSINE, other metrics take them
+		cosine = map[string]bool{}
+	)
+	for _, fp := range c.plan.in {
+		if !isVector(fp.field.DataType) {
+			continue
+		}
+		if index, err := c.fieldIndex(fp.field); err == nil {
+			cosine[fp.name] = indexMetricType(index) == entity.COSINE
+		}
+	}
+	for row, _v := range c.plan.rows(reflect.ValueOf(&models).Elem()) {
+		if !_v.IsValid() {
+			violations = append(violations, Violation{Row: row, Err: errNilModel})
+			continue
+		}
+		var pk interface{}
+		if fp, ok := c.plan.fields[c.pkFieldName]; ok {
+			pk = fp.value(_v).Interface()
+			if first, ok := pks[pk]; ok {
+				violations = append(violations, Violation{Row: row, PK: pk, Field: c.pkFieldName, Err: fmt.Errorf("%w, first seen at row %d", errDuplicate, first)})
+			} else {
+				pks[pk] = row
+			}
+		}
+		for _, fp := range c.plan.in {
+			if err := validateField(fp.field, row, fp.value(_v), cosine[fp.name]); err != nil {
+				violations = append(violations, Violation{Row: row, PK: pk, Field: fp.name, Err: err})
+			}
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: violations}
+}
+
+// validateField checks one value against the schema field it is written to, cosine rejects zero vectors
+func validateField(f *entity.Field, row int, value reflect.Value, cosine bool) error {
+	// registered field types are checked by their marshal func, when the columns are built
+	switch kind := value.Kind(); f.DataType {
+	case entity.FieldTypeVarChar, entity.FieldTypeString:
+		if maxLength, err := strconv.Atoi(f.TypeParams[entity.TypeParamMaxLength]); err == nil && kind == reflect.String && value.Len() > maxLength {
+			return fmt.Errorf("%d bytes exceed max_length %d", value.Len(), maxLength)
+		}
+	case entity.FieldTypeFloat, entity.FieldTypeDouble:
+		if kind != reflect.Float32 && kind != reflect.Float64 {
+			break
+		}
+		if x := value.Float(); math.IsNaN(x) || math.IsInf(x, 0) {
+			return errNaN
+		}
+	case entity.FieldTypeInt64:
+		if (kind == reflect.Uint || kind == reflect.Uint64) && value.Uint() > math.MaxInt64 {
+			return fmt.Errorf("%d overflows int64", value.Uint())
+		}
+	case entity.FieldTypeFloatVector, entity.FieldTypeFloat16Vector, entity.FieldTypeBFloat16Vector:
+		dim, _ := strconv.Atoi(f.TypeParams[entity.TypeParamDim])
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			// raw bytes of a half vector
+			dim *= 2
+		}
+		if value.Len() != dim {
+			return &ErrDimMismatch{Field: f.Name, Want: dim, Got: value.Len(), Row: row}
+		}
+		if elem := value.Type().Elem().Kind(); elem != reflect.Float32 && elem != reflect.Float64 {
+			// raw bits of a half vector
+			break
+		}
+		var norm float64
+		for i := 0; i < value.Len(); i++ {
+			x := value.Index(i).Float()
+			if math.IsNaN(x) || math.IsInf(x, 0) {
+				return fmt.Errorf("%w at index %d", errNaN, i)
+			}
+			norm += x * x
+		}
+		if norm == 0 && cosine {
+			return errZeroVector
+		}
+	case entity.FieldTypeBinaryVector:
+		dim, _ := strconv.Atoi(f.TypeParams[entity.TypeParamDim])
+		if value.Len() != dim/8 {
+			return &ErrDimMismatch{Field: f.Name, Want: dim / 8, Got: value.Len(), Row: row}
+		}
+	}
+	return nil
+}
+
+// validateForUpsert applies the validation mode to the models about to be upserted.
+// it returns the models to write, and the error to return once they are written
+func (c *Collection[v]) validateForUpsert(models []v) (valid []v, dropped *ValidationError, err error) {
+	if c.validationMode == ValidateOff {
+		return models, nil, nil
+	}
+	err = c.Validate(models...)
+	if err == nil {
+		return models, nil, nil
+	}
+	if c.validationMode != ValidateDrop {
+		return nil, nil, err
+	}
+	dropped = err.(*ValidationError)
+	dropped.Dropped = true
+	invalid := dropped.invalidRows()
+	valid = make([]v, 0, len(models)-len(invalid))
+	for row, m := range models {
+		if !invalid[row] {
+			valid = append(valid, m)
+		}
+	}
+	return valid, dropped, nil
+}
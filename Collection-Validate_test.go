@@ -0,0 +1,57 @@
+package qmilvus
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+type ValidatedDoc struct {
+	Id     int64     `milvus:"in,out,PK"`
+	Title  string    `milvus:"in,out,max_length=8"`
+	Rank   float32   `milvus:"in,out"`
+	Vector []float32 `milvus:"in,dim=4"`
+}
+
+func TestValidate(t *testing.T) {
+	cosine, _ := entity.NewIndexHNSW(entity.COSINE, 16, 200)
+	c := NewCollection[*ValidatedDoc](milvusAdress).WithFieldIndex("Vector", cosine)
+	docs := []*ValidatedDoc{
+		{Id: 1, Title: "ok", Vector: []float32{1, 0, 0, 0}},
+		{Id: 2, Title: strings.Repeat("é", 5), Vector: []float32{1, 0, 0, 0}}, // 10 bytes
+		{Id: 3, Rank: float32(math.NaN()), Vector: []float32{0, 0, 0, 0}},
+		nil,
+		{Id: 1, Vector: []float32{1, 2}},
+	}
+	err := c.Validate(docs...)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *ValidationError, got %v", err)
+	}
+	got := map[string]bool{}
+	for _, v := range verr.Violations {
+		got[v.Field+"@"+string(rune('0'+v.Row))] = true
+	}
+	for _, want := range []string{"Title@1", "Rank@2", "Vector@2", "@3", "Id@4", "Vector@4"} {
+		if !got[want] {
+			t.Errorf("missing violation %s in %v", want, err)
+		}
+	}
+	var dimErr *ErrDimMismatch
+	if !errors.As(err, &dimErr) || dimErr.Row != 4 {
+		t.Errorf("expected ErrDimMismatch of row 4 in the chain")
+	}
+
+	// zero vectors are fine under the default L2 index
+	if err := NewCollection[*ValidatedDoc](milvusAdress).Validate(&ValidatedDoc{Id: 1, Vector: make([]float32, 4)}); err != nil {
+		t.Errorf("a zero vector should be valid under L2, got %v", err)
+	}
+
+	valid, dropped, err := c.WithValidation(ValidateDrop).validateForUpsert(docs)
+	if err != nil || dropped == nil || len(valid) != 1 || valid[0].Id != 1 {
+		t.Errorf("drop mode should keep row 0 only, got %d rows, %v", len(valid), err)
+	}
+}
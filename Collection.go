@@ -3,14 +3,16 @@ package qmilvus
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"reflect"
-	"strconv"
 	"strings"
 	"sync"
-	"unicode"
+	"time"
 
 	"github.com/milvus-io/milvus-sdk-go/v2/client"
 	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // type v should contains fields Id Vector and Score
@@ -29,16 +31,59 @@ type Collection[v any] struct {
 	partitionName  string
 	collectionName string
 
-	IndexFieldName string
+	IndexFieldName string // default vector field, searched by SearchVector and indexed with Index
 	Index          entity.Index
 
+	indexes map[string]entity.Index // vector field name -> index built by Create, from WithFieldIndex
+
 	pkFieldName  string // 主键字段名 (通常由 Schema 定义)
 	schemaIn     *entity.Schema
 	outputFields []string
+	plan         *modelPlan // field access compiled by setInSchema
+
+	// connection, index cache and session, shared with the tenant handles
+	*shared
+
+	consistency Consistency
+
+	embedder       Embedder
+	embedBatchSize int
+	embedFrom      map[string]string // vector field name -> text field name, from tag embed_from=
+
+	validationMode ValidationMode
+
+	interceptors []Interceptor[v]
+
+	codec *Codec[v] // generated by qmilvus-gen, nil when columns are built by reflection
+
+	tenantField string       // field tagged tenant, or partition_key
+	tenant      *tenantScope // set on the handles returned by ForTenant
+
+	partitioner func(model v) string // names the partition of every written model, see WithPartitioner
+
+	retryPolicy *RetryPolicy
+	breaker     *CircuitBreaker
+	counters    *retryCounters
+
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry
+	logger         *slog.Logger
+}
+
+// shared is the state of a collection shared with its tenant handles
+type shared struct {
+	client   client.Client
+	clientMu sync.Mutex
 
-	client     client.Client
-	clientOnce sync.Once
-	clientErr  error
+	indexMu    sync.Mutex
+	indexCache map[string]entity.Index // field name -> index described by milvus
+
+	sessionMu sync.Mutex
+	sessionTs uint64 // timestamp of the latest write, guarantee timestamp of Session reads
+
+	partitionMu      sync.Mutex
+	partitionCreated map[string]bool // partitions created or seen by the writes of a partitioner
 }
 
 func (c *Collection[v]) WithContext(ctx context.Context) (ret *Collection[v]) {
@@ -56,6 +101,11 @@ func NewCollection[v any](milvusAdress string) (collection *Collection[v]) {
 	c.milvusAddress = milvusAdress
 	c.partitionName = "_default"
 	c.ctx = context.Background()
+	c.shared = &shared{}
+	c.retryPolicy = DefaultRetryPolicy
+	c.breaker = NewCircuitBreaker(5, 30*time.Second)
+	c.counters = &retryCounters{}
+	c.initTelemetry()
 
 	//take name of type v as collection name
 	_type := reflect.TypeOf((*v)(nil))
@@ -66,6 +116,11 @@ func NewCollection[v any](milvusAdress string) (collection *Collection[v]) {
 
 	c.setOutputFields()
 	c.setInSchema()
+	c.setCodec()
+	if c.hasPartitionKey() {
+		// milvus refuses partition names on collections partitioned by key
+		c.partitionName = ""
+	}
 	return c
 }
 func (collection *Collection[v]) WithPartitionName(partitionName string) (ret *Collection[v]) {
@@ -74,6 +129,8 @@ func (collection *Collection[v]) WithPartitionName(partitionName string) (ret *C
 }
 func (collection *Collection[v]) WithCollectionName(collectionName string) (ret *Collection[v]) {
 	collection.collectionName = collectionName
+	// the indexes cached are those of the previous collection
+	collection.ResetIndexCache()
 	return collection
 }
 func (collection *Collection[v]) WithCreateIndex(index entity.Index) (ret *Collection[v]) {
@@ -81,32 +138,39 @@ func (collection *Collection[v]) WithCreateIndex(index entity.Index) (ret *Colle
 	return collection
 }
 
+// WithFieldIndex sets the index Create builds on the vector field,
+// i.g. WithFieldIndex("BodyVec", entity.NewIndexHNSW(entity.COSINE, 16, 200))
+func (collection *Collection[v]) WithFieldIndex(field string, index entity.Index) (ret *Collection[v]) {
+	collection.mustVectorField(field)
+	if collection.indexes == nil {
+		collection.indexes = map[string]entity.Index{}
+	}
+	collection.indexes[field] = index
+	return collection
+}
+
+// WithDefaultVectorField sets the vector field searched by SearchVector, SearchBinary and SearchText, the first vector field by default
+func (collection *Collection[v]) WithDefaultVectorField(field string) (ret *Collection[v]) {
+	collection.mustVectorField(field)
+	collection.IndexFieldName = field
+	return collection
+}
+
 func (c *Collection[v]) setOutputFields() {
-	var (
-		structvalue reflect.Value
-		structType  reflect.Type
-	)
-	structType = reflect.TypeOf((*v)(nil))
+	structType := reflect.TypeOf((*v)(nil))
 	for structType.Kind() == reflect.Ptr || structType.Kind() == reflect.Slice {
 		structType = structType.Elem()
 	}
-	structvalue = reflect.New(structType).Elem()
 
 	c.outputFields = []string{}
-	for i := 0; i < structvalue.NumField(); i++ {
-		// gets us a StructField
-		tpi := structType.Field(i)
-		tagMilvus := strings.ToLower(tpi.Tag.Get("milvus"))
-		if strings.Contains(tagMilvus, "out") || strings.Contains(tagMilvus, "PK") {
-			c.outputFields = append(c.outputFields, tpi.Name)
+	walkFields(structType, func(name string, index []int, tpi reflect.StructField) {
+		if OutputField(tpi.Tag) {
+			c.outputFields = append(c.outputFields, name)
 		}
-	}
+	})
 }
 
 func (c *Collection[v]) setInSchema() {
-	var (
-		tagvalue string
-	)
 	_type := reflect.TypeOf((*v)(nil))
 	for _type.Kind() == reflect.Ptr || _type.Kind() == reflect.Slice {
 		_type = _type.Elem()
@@ -118,75 +182,101 @@ func (c *Collection[v]) setInSchema() {
 		AutoID:         false,
 		Fields:         []*entity.Field{},
 	}
+	c.plan = newModelPlan(_type, reflect.TypeOf((*v)(nil)).Elem().Kind() == reflect.Ptr)
 
-	for i := 0; i < _type.NumField(); i++ {
-		// gets us a StructField
-		tpi := _type.Field(i)
-		tagMilvus := strings.ToLower(tpi.Tag.Get("milvus"))
-		if tagMilvus == "" {
-			continue
+	// fields of embedded and prefixed nested structs are flattened, their name is prefixed then
+	walkFields(_type, func(name string, index []int, tpi reflect.StructField) {
+		tpi.Index = index
+		c.plan.leaves[name] = tpi
+		spec, err := SchemaField(StructField{Name: name, Type: schemaType(tpi.Type), Tag: tpi.Tag})
+		if err != nil {
+			panic(err)
 		}
-		TypeParams := map[string]string{}
-		_fieldType := tpi.Type.String()
-		_primarykey := strings.Contains(tagMilvus, "pk") && (_fieldType == "int64" || _fieldType == "string")
-		if _primarykey {
+		if spec == nil {
+			return
+		}
+		field := spec.Field
+		if _, dup := c.plan.fields[name]; dup {
+			panic(fmt.Errorf("field %s of %s is declared twice, set a prefix on the nested structs", name, _type.Name()))
+		}
+		tagMilvus := strings.ToLower(tpi.Tag.Get("milvus"))
+		if field.PrimaryKey {
 			if c.pkFieldName != "" {
 				panic(fmt.Errorf("primarykey should be unique, only one field can be set as primary key"))
 			}
-			c.pkFieldName = tpi.Name
+			c.pkFieldName = name
 		}
-
-		var columeType entity.FieldType
-		if _fieldType == "int64" {
-			columeType = entity.FieldTypeInt64
-		} else if _fieldType == "string" {
-			columeType = entity.FieldTypeVarChar
-			if TypeParams[entity.TypeParamMaxLength] = tpi.Tag.Get(entity.TypeParamMaxLength); TypeParams[entity.TypeParamMaxLength] == "" {
-				TypeParams[entity.TypeParamMaxLength] = "65535"
-			}
-		} else if _fieldType == "float32" {
-			columeType = entity.FieldTypeFloat
-		} else if _fieldType == "float64" {
-			columeType = entity.FieldTypeDouble
-		} else if _fieldType == "[]float32" {
-			columeType = entity.FieldTypeFloatVector
-			//set `dim`  `max_capacity`
-			if _, val, ok := strings.Cut(tagMilvus, entity.TypeParamDim+"="); ok {
-				TypeParams[entity.TypeParamDim] = strings.TrimRightFunc(val, func(r rune) bool { return !unicode.IsNumber(r) })
-				if _, err := strconv.Atoi(TypeParams[entity.TypeParamDim]); err != nil {
-					panic(fmt.Errorf("%s %s is not set", tpi.Name, TypeParams[entity.TypeParamDim]))
+		if field.DataType == entity.FieldTypeFloatVector || field.DataType == entity.FieldTypeFloat16Vector || field.DataType == entity.FieldTypeBFloat16Vector {
+			//set `embed_from`, the text field the vector is embedded from, checked once all the fields are walked
+			if textField, ok := tagOption(tpi.Tag.Get("milvus"), "embed_from"); ok {
+				if tpi.Type.Elem().Kind() != reflect.Float32 {
+					panic(fmt.Errorf("%s embed_from=%s should be a []float32 vector", name, textField))
 				}
-			}
-
-		} else if _fieldType == "bool" {
-			columeType = entity.FieldTypeBool
-		} else if _fieldType == "int8" {
-			columeType = entity.FieldTypeInt8
-		} else if _fieldType == "int16" {
-			columeType = entity.FieldTypeInt16
-		} else if _fieldType == "int32" {
-			columeType = entity.FieldTypeInt32
-		} else if _fieldType == "byte" {
-			columeType = entity.FieldTypeBinaryVector
-			if strings.Contains(tagvalue, "ind") {
-				c.IndexFieldName = tpi.Name
-			}
-			//set `dim`  `max_capacity`
-			if _, val, ok := strings.Cut(tagMilvus, entity.TypeParamDim+"="); ok {
-				TypeParams[entity.TypeParamDim] = strings.TrimRightFunc(val, func(r rune) bool { return !unicode.IsNumber(r) })
-				if _, err := strconv.Atoi(TypeParams[entity.TypeParamDim]); err != nil {
-					panic(fmt.Errorf("%s %s is not set", tpi.Name, TypeParams[entity.TypeParamDim]))
+				if c.embedFrom == nil {
+					c.embedFrom = map[string]string{}
 				}
+				c.embedFrom[name] = textField
 			}
-		} else {
-			panic(fmt.Errorf("PrimaryKey should be unique, with type int64 or string, unsupported type %s", _fieldType))
 		}
 
-		field := &entity.Field{Name: tpi.Name, DataType: columeType, PrimaryKey: _primarykey, AutoID: false, TypeParams: TypeParams}
-		if strings.Contains(tagMilvus, "in") || _primarykey {
+		//set `tenant`, the field ForTenant scopes to. tenant is preferred over partition_key
+		kind := tpi.Type.Kind()
+		tenantKind := kind == reflect.String || (kind >= reflect.Int && kind <= reflect.Int64)
+		if field.IsPartitionKey && c.tenantField == "" && tenantKind {
+			c.tenantField = name
+		}
+		if tagFlag(tagMilvus, "tenant") {
+			if !tenantKind {
+				panic(fmt.Errorf("tenant field %s should be int64 or string", name))
+			}
+			c.tenantField = name
+		}
+		if spec.In {
 			c.schemaIn.Fields = append(c.schemaIn.Fields, field)
 		}
+		c.plan.add(name, index, spec)
+	})
+	// the text fields are named as the columns are, prefixed when nested
+	for vectorField, textField := range c.embedFrom {
+		if f, ok := c.plan.leaves[textField]; !ok || f.Type.Kind() != reflect.String {
+			panic(fmt.Errorf("%s embed_from=%s should name a string field of %s", vectorField, textField, _type.Name()))
+		}
+	}
+}
+
+// tagFlag reports whether flag is one of the comma separated options of a milvus tag
+func tagFlag(tag string, flag string) bool {
+	for _, opt := range strings.Split(tag, ",") {
+		if strings.EqualFold(strings.TrimSpace(opt), flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// partitions returns the partitions searched and queried, none on collections partitioned by key
+func (c *Collection[v]) partitions() []string {
+	if c.partitionName == "" {
+		return nil
+	}
+	return []string{c.partitionName}
+}
 
+func (c *Collection[v]) hasPartitionKey() bool {
+	for _, f := range c.schemaIn.Fields {
+		if f.IsPartitionKey {
+			return true
+		}
 	}
+	return false
+}
 
+// tagOption returns the value of key=value in a milvus tag, the value keeps its case
+func tagOption(tag string, key string) (value string, ok bool) {
+	for _, opt := range strings.Split(tag, ",") {
+		if k, val, found := strings.Cut(strings.TrimSpace(opt), "="); found && strings.EqualFold(k, key) {
+			return val, true
+		}
+	}
+	return "", false
 }
@@ -2,7 +2,9 @@ package qmilvus
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/milvus-io/milvus-sdk-go/v2/client"
@@ -12,23 +14,41 @@ import (
 // NewGrpcClient : return a client with collection loaded
 // data loaded to memory every 10 minutes
 func (c *Collection[v]) NewGrpcClient(ctx context.Context) (_client client.Client, err error) {
-	opCtx, opCancel := context.WithTimeout(context.Background(), 10*time.Second) // 10秒操作超时
-	defer opCancel()
-	_client, err = client.NewGrpcClient(opCtx, c.milvusAddress,
-		grpc.WithBlock(), // 阻塞直到连接成功或超时
-	)
-
+	_client, err = Dial(c.milvusAddress, grpc.WithChainUnaryInterceptor(c.sessionInterceptor))
 	if err != nil {
 		// 检查错误是否是上下文超时导致的
-		if err == context.DeadlineExceeded {
-			log.Printf("ERROR: 连接 Milvus (%s) 超时，耗时超过 10 秒。错误信息：%v", c.milvusAddress, err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			c.logger.Error("connect milvus timed out", "address", c.milvusAddress, "timeout", dialTimeout, "error", err)
 		} else {
 			// 其他类型的连接错误
-			log.Printf("ERROR: 连接 Milvus (%s) 失败。错误信息：%v", c.milvusAddress, err)
+			c.logger.Error("connect milvus failed", "address", c.milvusAddress, "error", err)
 		}
-		return nil, err // 返回错误，不返回客户端
+		return nil, err
 	}
 
-	log.Printf("INFO: 成功连接到 Milvus (%s)。", c.milvusAddress)
+	c.logger.Debug("connected to milvus", "address", c.milvusAddress)
+	return _client, nil
+}
+
+// dialTimeout bounds the connection to milvus
+const dialTimeout = 10 * time.Second
+
+// Dial connects to the milvus at address as the collections do: the port is 19530 unless given,
+// failed milvus statuses are returned as *StatusError, connection failures wrap ErrUnavailable.
+// opts are appended to the dial options, e.g. more interceptors
+func Dial(address string, opts ...grpc.DialOption) (client.Client, error) {
+	if !strings.Contains(address, ":") {
+		address = address + ":19530"
+	}
+	opCtx, opCancel := context.WithTimeout(context.Background(), dialTimeout) // 10秒操作超时
+	defer opCancel()
+	opts = append([]grpc.DialOption{
+		grpc.WithBlock(), // 阻塞直到连接成功或超时
+		grpc.WithChainUnaryInterceptor(statusInterceptor),
+	}, opts...)
+	_client, err := client.NewGrpcClient(opCtx, address, opts...)
+	if err != nil {
+		return nil, &kindError{kind: ErrUnavailable, err: fmt.Errorf("connect milvus %s: %w", address, err)} // 返回错误，不返回客户端
+	}
 	return _client, nil
 }
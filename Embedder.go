@@ -0,0 +1,68 @@
+package qmilvus
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+)
+
+// Embedder turns texts into vectors, one vector per text, in the same order
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedderFunc adapts a function to the Embedder interface
+type EmbedderFunc func(ctx context.Context, texts []string) ([][]float32, error)
+
+func (f EmbedderFunc) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return f(ctx, texts)
+}
+
+// HashEmbedder is a deterministic Embedder for tests:
+// every lower cased word is hashed into one of Dim buckets with a hashed sign, then the vector is L2 normalized.
+// texts sharing words get similar vectors, an empty text gets the zero vector
+type HashEmbedder struct {
+	Dim int
+}
+
+// NewHashEmbedder returns a HashEmbedder of dim dimensions, dim should be positive
+func NewHashEmbedder(dim int) *HashEmbedder {
+	if dim <= 0 {
+		panic(fmt.Errorf("hash embedder dim should be positive, got %d", dim))
+	}
+	return &HashEmbedder{Dim: dim}
+}
+
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.Dim <= 0 {
+		return nil, fmt.Errorf("hash embedder dim should be positive, got %d: %w", e.Dim, ErrInvalidArgument)
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vec := make([]float32, e.Dim)
+		for _, word := range strings.Fields(strings.ToLower(text)) {
+			h := fnv.New64a()
+			h.Write([]byte(word))
+			sum := h.Sum64()
+			if sum>>63 == 1 {
+				vec[sum%uint64(e.Dim)] -= 1
+			} else {
+				vec[sum%uint64(e.Dim)] += 1
+			}
+		}
+		var norm float64
+		for _, x := range vec {
+			norm += float64(x) * float64(x)
+		}
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for j := range vec {
+				vec[j] = float32(float64(vec[j]) / norm)
+			}
+		}
+		vectors[i] = vec
+	}
+	return vectors, nil
+}
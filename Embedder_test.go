@@ -0,0 +1,96 @@
+package qmilvus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+type EmbeddedNote struct {
+	Id     int64     `milvus:"in,out,PK"`
+	Text   string    `milvus:"in,out"`
+	Vector []float32 `milvus:"in,dim=16,embed_from=Text"`
+}
+
+func TestHashEmbedderDeterministic(t *testing.T) {
+	e := NewHashEmbedder(16)
+	a, _ := e.Embed(context.Background(), []string{"hello milvus", ""})
+	b, _ := e.Embed(context.Background(), []string{"Hello Milvus", ""})
+	if !reflect.DeepEqual(a, b) {
+		t.Error("hash embedder should be deterministic and case insensitive")
+	}
+	for _, x := range a[1] {
+		if x != 0 {
+			t.Fatal("empty text should embed to the zero vector")
+		}
+	}
+}
+
+func TestHashEmbedderDim(t *testing.T) {
+	if _, err := (&HashEmbedder{}).Embed(context.Background(), []string{"a"}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("zero dim should be rejected, got %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("NewHashEmbedder should panic on a zero dim")
+		}
+	}()
+	NewHashEmbedder(0)
+}
+
+func TestFillEmbeddings(t *testing.T) {
+	c := NewCollection[*EmbeddedNote](milvusAdress).WithEmbedder(NewHashEmbedder(16), 1)
+	kept := make([]float32, 16)
+	notes := []*EmbeddedNote{{Id: 1, Text: "a"}, {Id: 2, Text: "b", Vector: kept}, {Id: 3, Text: "c"}}
+	if err := c.fillEmbeddings(context.Background(), notes); err != nil {
+		t.Fatal(err)
+	}
+	if len(notes[0].Vector) != 16 || len(notes[2].Vector) != 16 {
+		t.Error("missing vectors should be embedded")
+	}
+	if &notes[1].Vector[0] != &kept[0] {
+		t.Error("existing vectors should be kept")
+	}
+
+	wrongDim := NewCollection[*EmbeddedNote](milvusAdress).WithEmbedder(NewHashEmbedder(8))
+	if err := wrongDim.fillEmbeddings(context.Background(), []*EmbeddedNote{{Id: 1}}); err == nil {
+		t.Error("expected error on embedder dim mismatch")
+	}
+}
+
+func TestEmptyTextEmbedding(t *testing.T) {
+	// the hash embedder embeds an empty text to the zero vector: written under L2, rejected under COSINE
+	notes := []*EmbeddedNote{{Id: 1}}
+	c := NewCollection[*EmbeddedNote](milvusAdress).WithEmbedder(NewHashEmbedder(16))
+	if err := c.fillEmbeddings(context.Background(), notes); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Validate(notes...); err != nil {
+		t.Errorf("the zero vector should be valid under L2, got %v", err)
+	}
+	cosine, _ := entity.NewIndexHNSW(entity.COSINE, 16, 200)
+	if err := c.WithFieldIndex("Vector", cosine).Validate(notes...); !errors.Is(err, errZeroVector) {
+		t.Errorf("the zero vector should be rejected under COSINE, got %v", err)
+	}
+}
+
+type NestedNote struct {
+	Id     int64     `milvus:"in,out,PK"`
+	Owner  Ownership `milvus:"prefix=owner_"`
+	Vector []float32 `milvus:"in,dim=16,embed_from=owner_Team"`
+}
+
+func TestFillEmbeddingsNested(t *testing.T) {
+	c := NewCollection[*NestedNote](milvusAdress).WithEmbedder(NewHashEmbedder(16))
+	notes := []*NestedNote{{Id: 1, Owner: Ownership{Team: "search"}}}
+	if err := c.fillEmbeddings(context.Background(), notes); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := NewHashEmbedder(16).Embed(context.Background(), []string{"search"})
+	if !reflect.DeepEqual(notes[0].Vector, want[0]) {
+		t.Error("the vector should be embedded from the prefixed nested text field")
+	}
+}
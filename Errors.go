@@ -0,0 +1,196 @@
+package qmilvus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errors returned by Collection methods, test them with errors.Is.
+// the original milvus error stays in the chain, use errors.As with *StatusError to get the status code
+var (
+	ErrCollectionNotFound = errors.New("collection not found")
+	ErrPartitionNotFound  = errors.New("partition not found")
+	ErrIndexNotFound      = errors.New("index not found")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrNotLoaded          = errors.New("collection not loaded")
+	ErrRateLimited        = errors.New("rate limited")
+	ErrQuotaExceeded      = errors.New("quota exceeded")
+	ErrUnavailable        = errors.New("milvus unavailable")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrInvalidArgument    = errors.New("invalid argument")
+	ErrCorruptBackup      = errors.New("corrupt backup") // a data file of a backup fails its checksum
+)
+
+// ErrDimMismatch reports a vector whose length differs from the dim of its field
+type ErrDimMismatch struct {
+	Field     string
+	Want, Got int
+	Row       int
+}
+
+func (e *ErrDimMismatch) Error() string {
+	return fmt.Sprintf("field %s row %d: vector dim %d, want %d", e.Field, e.Row, e.Got, e.Want)
+}
+
+// Is makes a dim mismatch an ErrInvalidArgument
+func (e *ErrDimMismatch) Is(target error) bool {
+	return target == ErrInvalidArgument
+}
+
+// StatusError is a failed status returned by milvus
+type StatusError struct {
+	Method    string // grpc method, e.g. /milvus.proto.milvus.MilvusService/Search
+	Code      int32  // milvus error code, set by milvus 2.3 and later
+	ErrorCode commonpb.ErrorCode
+	Reason    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: %s (code %d, %s)", e.Method, e.Reason, e.Code, e.ErrorCode)
+}
+
+// Is matches the package error the status belongs to
+func (e *StatusError) Is(target error) bool {
+	return target != nil && statusKind(e.Code, e.ErrorCode, e.Reason) == target
+}
+
+// statusKind maps a milvus status to the package error values.
+// Code is checked first, ErrorCode is the legacy code of milvus 2.2, Reason is the last resort
+func statusKind(code int32, errorCode commonpb.ErrorCode, reason string) error {
+	switch code {
+	case 1, 2, 106: // service not ready, service unavailable, collection on recovering
+		return ErrUnavailable
+	case 4, 8, 11: // request limit exceeded, rate limit, time tick long delay
+		return ErrRateLimited
+	case 3, 7, 9: // memory limit, disk limit, quota exceeded
+		return ErrQuotaExceeded
+	case 100:
+		return ErrCollectionNotFound
+	case 101, 103, 201, 202: // collection / partition not (fully) loaded
+		return ErrNotLoaded
+	case 200:
+		return ErrPartitionNotFound
+	case 700:
+		return ErrIndexNotFound
+	case 1100, 1101: // parameter invalid, parameter missing
+		return ErrInvalidArgument
+	}
+
+	switch errorCode {
+	case commonpb.ErrorCode_CollectionNotExists, commonpb.ErrorCode_CollectionNameNotFound:
+		return ErrCollectionNotFound
+	case commonpb.ErrorCode_IndexNotExist:
+		return ErrIndexNotFound
+	case commonpb.ErrorCode_RateLimit, commonpb.ErrorCode_TimeTickLongDelay:
+		return ErrRateLimited
+	case commonpb.ErrorCode_ForceDeny, commonpb.ErrorCode_MemoryQuotaExhausted, commonpb.ErrorCode_DiskQuotaExhausted, commonpb.ErrorCode_InsufficientMemoryToLoad:
+		return ErrQuotaExceeded
+	case commonpb.ErrorCode_ConnectFailed, commonpb.ErrorCode_NotReadyServe, commonpb.ErrorCode_NotReadyCoordActivating, commonpb.ErrorCode_NoReplicaAvailable, commonpb.ErrorCode_NotShardLeader:
+		return ErrUnavailable
+	case commonpb.ErrorCode_PermissionDenied:
+		return ErrPermissionDenied
+	case commonpb.ErrorCode_IllegalArgument, commonpb.ErrorCode_IllegalDimension, commonpb.ErrorCode_IllegalIndexType, commonpb.ErrorCode_IllegalCollectionName,
+		commonpb.ErrorCode_IllegalTOPK, commonpb.ErrorCode_IllegalRowRecord, commonpb.ErrorCode_IllegalVectorID, commonpb.ErrorCode_IllegalNLIST,
+		commonpb.ErrorCode_IllegalMetricType, commonpb.ErrorCode_UpsertAutoIDTrue:
+		return ErrInvalidArgument
+	}
+	return reasonKind(reason)
+}
+
+// reasonKind classifies errors that carry no code, e.g. from older servers or from sdk side checks
+func reasonKind(reason string) error {
+	reason = strings.ToLower(reason)
+	switch {
+	case strings.Contains(reason, "already exist"):
+		return ErrAlreadyExists
+	case strings.Contains(reason, "not loaded"):
+		return ErrNotLoaded
+	case strings.Contains(reason, "index not found") || strings.Contains(reason, "index not exist"):
+		return ErrIndexNotFound
+	}
+	return nil
+}
+
+// kindError ties an error to the package error value it belongs to
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string { return e.err.Error() }
+func (e *kindError) Unwrap() error { return e.err }
+func (e *kindError) Is(target error) bool {
+	return target == e.kind
+}
+
+// wrapError classifies errors returned by the sdk, so errors.Is works with the package error values.
+// errors already classified, and context errors, are returned as is
+func wrapError(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	var (
+		kind          error
+		statusErr     *StatusError
+		dimErr        *ErrDimMismatch
+		kindErr       *kindError
+		collNotExists client.ErrCollectionNotExists
+		partNotExists client.ErrPartitionNotExists
+	)
+	switch {
+	case errors.As(err, &statusErr), errors.As(err, &dimErr), errors.As(err, &kindErr):
+		return err
+	case errors.As(err, &collNotExists):
+		kind = ErrCollectionNotFound
+	case errors.As(err, &partNotExists):
+		kind = ErrPartitionNotFound
+	case errors.Is(err, client.ErrClientNotReady):
+		kind = ErrUnavailable
+	default:
+		if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+			switch s.Code() {
+			case codes.Unavailable:
+				kind = ErrUnavailable
+			case codes.ResourceExhausted:
+				kind = ErrRateLimited
+			case codes.PermissionDenied, codes.Unauthenticated:
+				kind = ErrPermissionDenied
+			case codes.InvalidArgument:
+				kind = ErrInvalidArgument
+			}
+		} else {
+			kind = reasonKind(err.Error())
+		}
+	}
+	if kind == nil {
+		return err
+	}
+	return &kindError{kind: kind, err: err}
+}
+
+// statusInterceptor turns failed milvus statuses into *StatusError,
+// the sdk would otherwise keep only the reason text
+func statusInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if err := invoker(ctx, method, req, reply, cc, opts...); err != nil {
+		return err
+	}
+	var s *commonpb.Status
+	switch r := reply.(type) {
+	case *commonpb.Status:
+		s = r
+	case interface{ GetStatus() *commonpb.Status }:
+		s = r.GetStatus()
+	}
+	if s != nil && (s.GetErrorCode() != commonpb.ErrorCode_Success || s.GetCode() != 0) {
+		return &StatusError{Method: method, Code: s.GetCode(), ErrorCode: s.GetErrorCode(), Reason: s.GetReason()}
+	}
+	return nil
+}
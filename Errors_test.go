@@ -0,0 +1,46 @@
+package qmilvus
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+)
+
+func TestStatusErrorIs(t *testing.T) {
+	cases := []struct {
+		err  *StatusError
+		kind error
+	}{
+		{&StatusError{Code: 100, ErrorCode: commonpb.ErrorCode_UnexpectedError, Reason: "collection not found[collection=x]"}, ErrCollectionNotFound},
+		{&StatusError{Code: 101}, ErrNotLoaded},
+		{&StatusError{ErrorCode: commonpb.ErrorCode_RateLimit}, ErrRateLimited},
+		{&StatusError{ErrorCode: commonpb.ErrorCode_UnexpectedError, Reason: "partition already exists"}, ErrAlreadyExists},
+	}
+	for _, c := range cases {
+		wrapped := fmt.Errorf("upsert: %w", wrapError(c.err))
+		if !errors.Is(wrapped, c.kind) {
+			t.Errorf("%v should be %v", c.err, c.kind)
+		}
+		var statusErr *StatusError
+		if !errors.As(wrapped, &statusErr) || statusErr.Code != c.err.Code {
+			t.Errorf("%v should keep its status code", c.err)
+		}
+	}
+	if errors.Is(wrapError(errors.New("boom")), ErrUnavailable) {
+		t.Error("unknown errors should stay unclassified")
+	}
+}
+
+func TestBuildColumnsDimMismatch(t *testing.T) {
+	c := NewCollection[*OggAction](milvusAdress)
+	_, err := c.BuildColumns(&OggAction{Id: 1, Vector: randomVector(768)}, &OggAction{Id: 2, Vector: randomVector(3)})
+	var dimErr *ErrDimMismatch
+	if !errors.As(err, &dimErr) || dimErr.Row != 1 || dimErr.Got != 3 || dimErr.Want != 768 {
+		t.Fatalf("expected ErrDimMismatch on row 1, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Error("dim mismatch should be an ErrInvalidArgument")
+	}
+}
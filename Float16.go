@@ -0,0 +1,139 @@
+package qmilvus
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Float16 and BFloat16 vectors are sent to milvus as 2 little endian bytes per dim.
+// the model field is a []float32, converted, or the raw []uint16 bits, or the raw bytes
+
+// Float32ToFloat16 encodes x as float16, rounding to the nearest even
+func Float32ToFloat16(x []float32) []byte {
+	return encodeHalf(x, float16Bits)
+}
+
+// Float16ToFloat32 decodes the float16 vector b
+func Float16ToFloat32(b []byte) []float32 {
+	return decodeHalf(b, float16Float)
+}
+
+// Float32ToBFloat16 encodes x as bfloat16, rounding to the nearest even
+func Float32ToBFloat16(x []float32) []byte {
+	return encodeHalf(x, bfloat16Bits)
+}
+
+// BFloat16ToFloat32 decodes the bfloat16 vector b
+func BFloat16ToFloat32(b []byte) []float32 {
+	return decodeHalf(b, func(h uint16) float32 { return math.Float32frombits(uint32(h) << 16) })
+}
+
+// Uint16sToBytes returns the raw bits x of a float16 or bfloat16 vector as milvus stores them
+func Uint16sToBytes(x []uint16) []byte {
+	if x == nil {
+		return nil
+	}
+	b := make([]byte, 2*len(x))
+	for i, h := range x {
+		binary.LittleEndian.PutUint16(b[2*i:], h)
+	}
+	return b
+}
+
+// BytesToUint16s returns the raw bits of the float16 or bfloat16 vector b
+func BytesToUint16s(b []byte) []uint16 {
+	if b == nil {
+		return nil
+	}
+	x := make([]uint16, len(b)/2)
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint16(b[2*i:])
+	}
+	return x
+}
+
+func encodeHalf(x []float32, bits func(float32) uint16) []byte {
+	if x == nil {
+		return nil
+	}
+	b := make([]byte, 2*len(x))
+	for i, f := range x {
+		binary.LittleEndian.PutUint16(b[2*i:], bits(f))
+	}
+	return b
+}
+
+func decodeHalf(b []byte, float func(uint16) float32) []float32 {
+	if b == nil {
+		return nil
+	}
+	x := make([]float32, len(b)/2)
+	for i := range x {
+		x[i] = float(binary.LittleEndian.Uint16(b[2*i:]))
+	}
+	return x
+}
+
+// float16Bits returns the IEEE 754 half precision bits of f: overflows are Inf, underflows 0
+func float16Bits(f float32) uint16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int(b>>23&0xff) - 127 + 15
+	mant := b & 0x7fffff
+	switch {
+	case b>>23&0xff == 0xff:
+		// Inf, NaN stays a quiet NaN
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	case exp >= 0x1f:
+		return sign | 0x7c00
+	case exp <= 0:
+		// subnormal, the implicit leading 1 made explicit
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint(14 - exp)
+		half := uint16(mant >> shift)
+		if rem, mid := mant&(1<<shift-1), uint32(1)<<(shift-1); rem > mid || rem == mid && half&1 == 1 {
+			half++
+		}
+		return sign | half
+	}
+	half := sign | uint16(exp)<<10 | uint16(mant>>13)
+	// a carry out of the mantissa rounds up the exponent, up to Inf
+	if rem := mant & 0x1fff; rem > 0x1000 || rem == 0x1000 && half&1 == 1 {
+		half++
+	}
+	return half
+}
+
+func float16Float(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+	switch exp {
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	case 0:
+		// zero and subnormals, mant * 2^-24
+		f := float32(mant) / (1 << 24)
+		if sign != 0 {
+			f = -f
+		}
+		return f
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
+
+// bfloat16Bits returns the upper half of the float32 bits of f, rounded to the nearest even
+func bfloat16Bits(f float32) uint16 {
+	b := math.Float32bits(f)
+	if b&0x7fffffff > 0x7f800000 {
+		return uint16(b>>16) | 0x40
+	}
+	b += 0x7fff + b>>16&1
+	return uint16(b >> 16)
+}
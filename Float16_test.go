@@ -0,0 +1,93 @@
+package qmilvus
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+func TestFloat16Precision(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	x := make([]float32, 4096)
+	for i := range x {
+		x[i] = float32(rnd.NormFloat64() * 100)
+	}
+	for _, half := range []struct {
+		name      string
+		encode    func([]float32) []byte
+		decode    func([]byte) []float32
+		precision float64 // relative error bound, half an ulp
+	}{{"float16", Float32ToFloat16, Float16ToFloat32, 1.0 / (1 << 11)}, {"bfloat16", Float32ToBFloat16, BFloat16ToFloat32, 1.0 / (1 << 8)}} {
+		y := half.decode(half.encode(x))
+		for i := range x {
+			// float16 subnormals below 2^-14 have fewer bits, the relative bound does not hold
+			if math.Abs(float64(x[i])) < 1.0/(1<<14) {
+				continue
+			}
+			if e := math.Abs(float64(y[i]-x[i])) / math.Abs(float64(x[i])); e > half.precision {
+				t.Fatalf("%s: %v read back as %v, relative error %v", half.name, x[i], y[i], e)
+			}
+		}
+		// exact values round trip exactly
+		if y := half.decode(half.encode([]float32{0, 1, -2, 0.5, 3.75})); !reflect.DeepEqual(y, []float32{0, 1, -2, 0.5, 3.75}) {
+			t.Errorf("%s: exact values read back as %v", half.name, y)
+		}
+	}
+
+	specials := Float16ToFloat32(Float32ToFloat16([]float32{70000, float32(math.Inf(-1)), float32(math.NaN()), 1e-7, 1e-9, 65504, 1 + 1.0/2048}))
+	if !math.IsInf(float64(specials[0]), 1) || !math.IsInf(float64(specials[1]), -1) || !math.IsNaN(float64(specials[2])) {
+		t.Errorf("overflow, Inf and NaN should be kept, got %v", specials[:3])
+	}
+	if specials[3] == 0 || specials[4] != 0 || specials[5] != 65504 {
+		t.Errorf("subnormals and the largest float16 should be kept, got %v", specials[3:6])
+	}
+	if specials[6] != 1 {
+		t.Errorf("ties should round to even, got %v", specials[6])
+	}
+}
+
+type HalfDoc struct {
+	Id    int64     `milvus:"in,out,PK"`
+	Text  []float32 `milvus:"in,out,dim=4,vector=float16"`
+	Image []float64 `milvus:"in,out,dim=2,vector=bfloat16"`
+	Bits  []uint16  `milvus:"in,out,dim=2,vector=bfloat16"`
+	Raw   []byte    `milvus:"in,out,dim=2,vector=float16"`
+}
+
+func TestHalfVectors(t *testing.T) {
+	c := NewCollection[*HalfDoc](milvusAdress)
+	in := []*HalfDoc{{Id: 1, Text: []float32{0.1, 0.2, 0.3, 0.4}, Image: []float64{1.5, -2}, Bits: []uint16{0x3f80, 0x4000}, Raw: []byte{0, 0x3c, 0, 0x40}}}
+	columns, err := c.BuildColumns(in...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if columns[1].Type() != entity.FieldTypeFloat16Vector || columns[2].Type() != entity.FieldTypeBFloat16Vector {
+		t.Fatalf("unexpected column types %v %v", columns[1].Type(), columns[2].Type())
+	}
+	out, err := c.ParseSearchResult(&client.SearchResult{ResultCount: 1, Fields: columns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Text loses precision, the others are exact
+	for i, x := range out[0].Text {
+		if math.Abs(float64(x-in[0].Text[i])) > 1e-3 {
+			t.Errorf("Text read back as %v", out[0].Text)
+		}
+	}
+	out[0].Text = in[0].Text
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("models differ after a round trip: %+v", out[0])
+	}
+
+	if q, err := c.queryVector("Text", []float32{1, 2, 3, 4}); err != nil || q.FieldType() != entity.FieldTypeFloat16Vector || c.vectorFieldName() != "Text" {
+		t.Errorf("float32 queries of Text should be converted to float16")
+	}
+	in[0].Raw = in[0].Raw[:2]
+	if err = c.Validate(in...); err == nil {
+		t.Errorf("a half vector of 2 bytes should fail a dim=2 field")
+	}
+}
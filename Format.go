@@ -0,0 +1,545 @@
+package qmilvus
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Format is the file format of Export, Import and bulk inserts, the rows are keyed by field name.
+// float16 and bfloat16 vectors are written as float32 values, binary vectors base64 encoded in jsonl and csv, as lists of bytes otherwise.
+// FormatJSON and FormatParquet files are the row-based files milvus bulk import reads
+type Format string
+
+const (
+	FormatJSONL   Format = "jsonl"   // one json object per line
+	FormatJSON    Format = "json"    // a json object {"rows": [...]}
+	FormatCSV     Format = "csv"     // a header line of the field names, vectors as json arrays
+	FormatParquet Format = "parquet" // vectors as lists
+)
+
+// recordWriter writes rows of column values keyed by field name
+type recordWriter interface {
+	Write(row map[string]interface{}) error
+	Close() error
+}
+
+// recordReader reads the rows of a file, io.EOF after the last one.
+// the values are loosely typed, e.g. json.Number or string, importValue converts them
+type recordReader interface {
+	Read() (map[string]interface{}, error)
+}
+
+func newRecordWriter(w io.Writer, format Format, fields []*entity.Field) (recordWriter, error) {
+	switch format {
+	case FormatJSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	case FormatJSON:
+		return &jsonWriter{w: w}, nil
+	case FormatCSV:
+		return newCSVWriter(w, fields)
+	case FormatParquet:
+		return newParquetWriter(w, fields)
+	}
+	return nil, fmt.Errorf("unknown format %q, use jsonl, json, csv or parquet: %w", format, ErrInvalidArgument)
+}
+
+func newRecordReader(r io.Reader, format Format, fields []*entity.Field) (recordReader, error) {
+	switch format {
+	case FormatJSONL:
+		dec := json.NewDecoder(r)
+		dec.UseNumber()
+		return &jsonlReader{dec: dec}, nil
+	case FormatJSON:
+		dec := json.NewDecoder(r)
+		dec.UseNumber()
+		return &jsonReader{dec: dec}, nil
+	case FormatCSV:
+		return newCSVReader(r, fields)
+	case FormatParquet:
+		return newParquetReader(r)
+	}
+	return nil, fmt.Errorf("unknown format %q, use jsonl, json, csv or parquet: %w", format, ErrInvalidArgument)
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func (w *jsonlWriter) Write(row map[string]interface{}) error { return w.enc.Encode(row) }
+func (w *jsonlWriter) Close() error                           { return nil }
+
+type jsonlReader struct {
+	dec *json.Decoder
+}
+
+func (r *jsonlReader) Read() (row map[string]interface{}, err error) {
+	err = r.dec.Decode(&row)
+	return row, err
+}
+
+type jsonWriter struct {
+	w    io.Writer
+	rows int
+}
+
+// Write writes the row as an element of the rows array, binary vectors as arrays of bytes
+func (w *jsonWriter) Write(row map[string]interface{}) error {
+	sep := ","
+	if w.rows == 0 {
+		sep = `{"rows":[`
+	}
+	for name, x := range row {
+		if b, ok := x.([]byte); ok {
+			bytes := make([]int, len(b))
+			for i := range b {
+				bytes[i] = int(b[i])
+			}
+			row[name] = bytes
+		}
+	}
+	element, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(w.w, sep+"\n"); err == nil {
+		_, err = w.w.Write(element)
+	}
+	w.rows++
+	return err
+}
+
+func (w *jsonWriter) Close() (err error) {
+	if w.rows == 0 {
+		_, err = io.WriteString(w.w, `{"rows":[]}`+"\n")
+		return err
+	}
+	_, err = io.WriteString(w.w, "\n]}\n")
+	return err
+}
+
+// jsonReader reads the elements of the rows array one by one
+type jsonReader struct {
+	dec     *json.Decoder
+	started bool
+}
+
+func (r *jsonReader) Read() (row map[string]interface{}, err error) {
+	if !r.started {
+		for _, want := range []interface{}{json.Delim('{'), "rows", json.Delim('[')} {
+			token, err := r.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			if token != want {
+				return nil, fmt.Errorf(`json file should be {"rows": [...]}, got %v: %w`, token, ErrInvalidArgument)
+			}
+		}
+		r.started = true
+	}
+	if !r.dec.More() {
+		return nil, io.EOF
+	}
+	err = r.dec.Decode(&row)
+	return row, err
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	fields []*entity.Field
+	record []string
+}
+
+func newCSVWriter(w io.Writer, fields []*entity.Field) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), fields: fields, record: make([]string, len(fields))}
+	for i, f := range fields {
+		cw.record[i] = f.Name
+	}
+	return cw, cw.w.Write(cw.record)
+}
+
+func (w *csvWriter) Write(row map[string]interface{}) (err error) {
+	for i, f := range w.fields {
+		if w.record[i], err = formatCell(row[f.Name]); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+	}
+	return w.w.Write(w.record)
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// formatCell formats a column value as a csv cell
+func formatCell(x interface{}) (string, error) {
+	switch x := x.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return x, nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	case int8:
+		return strconv.FormatInt(int64(x), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(x), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(x), 10), nil
+	case int64:
+		return strconv.FormatInt(x, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(x), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64), nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(x), nil
+	case []float32:
+		b, err := json.Marshal(x)
+		return string(b), err
+	}
+	return "", fmt.Errorf("unsupported value %T", x)
+}
+
+type csvReader struct {
+	r       *csv.Reader
+	header  []string
+	strings map[string]bool // varchar fields, whose empty cells are empty strings
+}
+
+func newCSVReader(r io.Reader, fields []*entity.Field) (*csvReader, error) {
+	cr := &csvReader{r: csv.NewReader(r), strings: map[string]bool{}}
+	for _, f := range fields {
+		cr.strings[f.Name] = f.DataType == entity.FieldTypeVarChar || f.DataType == entity.FieldTypeString
+	}
+	header, err := cr.r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read csv header: %w", err)
+	}
+	// the header is reused by csv.Reader
+	cr.header = append([]string(nil), header...)
+	return cr, nil
+}
+
+// Read returns the cells of a row, empty cells are left out but of varchar fields
+func (r *csvReader) Read() (map[string]interface{}, error) {
+	record, err := r.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	row := make(map[string]interface{}, len(record))
+	for i, cell := range record {
+		if cell != "" || r.strings[r.header[i]] {
+			row[r.header[i]] = cell
+		}
+	}
+	return row, nil
+}
+
+// parquetNode returns the parquet column of a milvus field, as milvus bulk import reads them
+func parquetNode(f *entity.Field) (parquet.Node, error) {
+	switch f.DataType {
+	case entity.FieldTypeBool:
+		return parquet.Leaf(parquet.BooleanType), nil
+	case entity.FieldTypeInt8:
+		return parquet.Int(8), nil
+	case entity.FieldTypeInt16:
+		return parquet.Int(16), nil
+	case entity.FieldTypeInt32:
+		return parquet.Int(32), nil
+	case entity.FieldTypeInt64:
+		return parquet.Int(64), nil
+	case entity.FieldTypeFloat:
+		return parquet.Leaf(parquet.FloatType), nil
+	case entity.FieldTypeDouble:
+		return parquet.Leaf(parquet.DoubleType), nil
+	case entity.FieldTypeVarChar, entity.FieldTypeString:
+		return parquet.String(), nil
+	case entity.FieldTypeFloatVector, entity.FieldTypeFloat16Vector, entity.FieldTypeBFloat16Vector:
+		return parquet.List(parquet.Leaf(parquet.FloatType)), nil
+	case entity.FieldTypeBinaryVector:
+		return parquet.List(parquet.Uint(8)), nil
+	}
+	return nil, fmt.Errorf("field %s of type %s has no parquet column: %w", f.Name, f.DataType.Name(), ErrInvalidArgument)
+}
+
+// parquetSchema returns the parquet schema of the fields
+func parquetSchema(name string, fields []*entity.Field) (*parquet.Schema, error) {
+	group := parquet.Group{}
+	for _, f := range fields {
+		node, err := parquetNode(f)
+		if err != nil {
+			return nil, err
+		}
+		group[f.Name] = node
+	}
+	return parquet.NewSchema(name, group), nil
+}
+
+type parquetWriter struct {
+	w *parquet.GenericWriter[map[string]any]
+}
+
+func newParquetWriter(w io.Writer, fields []*entity.Field) (*parquetWriter, error) {
+	schema, err := parquetSchema("rows", fields)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetWriter{w: parquet.NewGenericWriter[map[string]any](w, schema)}, nil
+}
+
+func (w *parquetWriter) Write(row map[string]interface{}) error {
+	_, err := w.w.Write([]map[string]any{row})
+	return err
+}
+
+func (w *parquetWriter) Close() error { return w.w.Close() }
+
+type parquetReader struct {
+	r    *parquet.GenericReader[map[string]any]
+	rows []map[string]any
+	next int
+}
+
+// newParquetReader reads r through its ReadAt method when it has one and knows its size, from memory otherwise
+func newParquetReader(r io.Reader) (*parquetReader, error) {
+	var (
+		ra   io.ReaderAt
+		size int64
+	)
+	switch r := r.(type) {
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil {
+			return nil, err
+		}
+		ra, size = r, info.Size()
+	case interface {
+		io.ReaderAt
+		Size() int64
+	}:
+		ra, size = r, r.Size()
+	default:
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		ra, size = bytes.NewReader(b), int64(len(b))
+	}
+	f, err := parquet.OpenFile(ra, size)
+	if err != nil {
+		return nil, fmt.Errorf("open parquet file: %w", err)
+	}
+	return &parquetReader{r: parquet.NewGenericReader[map[string]any](ra, f.Schema())}, nil
+}
+
+func (r *parquetReader) Read() (map[string]interface{}, error) {
+	if r.next == len(r.rows) {
+		rows := make([]map[string]any, 256)
+		for i := range rows {
+			rows[i] = map[string]any{}
+		}
+		n, err := r.r.Read(rows)
+		if n == 0 {
+			if err == nil {
+				err = io.EOF
+			}
+			return nil, err
+		}
+		r.rows, r.next = rows[:n], 0
+	}
+	row := r.rows[r.next]
+	r.next++
+	return row, nil
+}
+
+// exportValue returns the value of row i of column as written by Export, half vectors decoded to float32
+func exportValue(column entity.Column, i int) (interface{}, error) {
+	x, err := column.Get(i)
+	if err != nil {
+		return nil, err
+	}
+	switch column.Type() {
+	case entity.FieldTypeFloat16Vector:
+		return Float16ToFloat32(x.([]byte)), nil
+	case entity.FieldTypeBFloat16Vector:
+		return BFloat16ToFloat32(x.([]byte)), nil
+	}
+	return x, nil
+}
+
+// importValue converts x, read from a file, to the value newColumn expects for field:
+// bool, int64 for ints, float64 for floats, string, []float32 for float vectors and []byte for binary vectors
+func importValue(field *entity.Field, x interface{}) (interface{}, error) {
+	switch field.DataType {
+	case entity.FieldTypeBool:
+		switch x := x.(type) {
+		case bool:
+			return x, nil
+		case string:
+			return strconv.ParseBool(x)
+		}
+	case entity.FieldTypeInt8, entity.FieldTypeInt16, entity.FieldTypeInt32, entity.FieldTypeInt64:
+		i, err := toInt64(x)
+		if err != nil {
+			return nil, err
+		}
+		if bits := map[entity.FieldType]uint{entity.FieldTypeInt8: 8, entity.FieldTypeInt16: 16, entity.FieldTypeInt32: 32}[field.DataType]; bits > 0 && (i < -1<<(bits-1) || i >= 1<<(bits-1)) {
+			return nil, fmt.Errorf("%d overflows %s", i, field.DataType.Name())
+		}
+		return i, nil
+	case entity.FieldTypeFloat, entity.FieldTypeDouble:
+		return toFloat64(x)
+	case entity.FieldTypeVarChar, entity.FieldTypeString:
+		if s, ok := x.(string); ok {
+			return s, nil
+		}
+	case entity.FieldTypeFloatVector, entity.FieldTypeFloat16Vector, entity.FieldTypeBFloat16Vector:
+		return toFloat32s(x)
+	case entity.FieldTypeBinaryVector:
+		switch x := x.(type) {
+		case []byte:
+			return x, nil
+		case string:
+			return base64.StdEncoding.DecodeString(x)
+		case []interface{}:
+			// json and parquet lists of bytes
+			b := make([]byte, len(x))
+			for i, e := range x {
+				n, err := toInt64(e)
+				if err != nil || n < 0 || n > math.MaxUint8 {
+					return nil, fmt.Errorf("element %d of %s is not a byte: %v", i, field.Name, e)
+				}
+				b[i] = byte(n)
+			}
+			return b, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported field type %s", field.DataType.Name())
+	}
+	return nil, fmt.Errorf("%T is not a %s value", x, field.DataType.Name())
+}
+
+func toInt64(x interface{}) (int64, error) {
+	switch x := x.(type) {
+	case json.Number:
+		return x.Int64()
+	case string:
+		return strconv.ParseInt(x, 10, 64)
+	case int8:
+		return int64(x), nil
+	case int16:
+		return int64(x), nil
+	case int32:
+		return int64(x), nil
+	case int64:
+		return x, nil
+	case int:
+		return int64(x), nil
+	case uint8:
+		return int64(x), nil
+	case uint16:
+		return int64(x), nil
+	case uint32:
+		return int64(x), nil
+	case uint64:
+		if x <= math.MaxInt64 {
+			return int64(x), nil
+		}
+	case float64:
+		if x == math.Trunc(x) && math.Abs(x) < 1<<63 {
+			return int64(x), nil
+		}
+	}
+	return 0, fmt.Errorf("%v is not an integer", x)
+}
+
+func toFloat64(x interface{}) (float64, error) {
+	switch x := x.(type) {
+	case json.Number:
+		return x.Float64()
+	case string:
+		return strconv.ParseFloat(x, 64)
+	case float32:
+		return float64(x), nil
+	case float64:
+		return x, nil
+	case int32:
+		return float64(x), nil
+	case int64:
+		return float64(x), nil
+	}
+	return 0, fmt.Errorf("%v is not a number", x)
+}
+
+func toFloat32s(x interface{}) (vector []float32, err error) {
+	switch x := x.(type) {
+	case []float32:
+		return x, nil
+	case string:
+		// csv cells hold json arrays
+		err = json.Unmarshal([]byte(x), &vector)
+		return vector, err
+	case []interface{}:
+		vector = make([]float32, len(x))
+		for i, e := range x {
+			f, err := toFloat64(e)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			vector[i] = float32(f)
+		}
+		return vector, nil
+	}
+	return nil, fmt.Errorf("%T is not a vector", x)
+}
+
+// newColumn builds the column of field from values converted by importValue
+func newColumn(field *entity.Field, values []interface{}) (entity.Column, error) {
+	dim, _ := strconv.Atoi(field.TypeParams[entity.TypeParamDim])
+	switch field.DataType {
+	case entity.FieldTypeBool:
+		return entity.NewColumnBool(field.Name, typed[bool](values, func(x interface{}) bool { return x.(bool) })), nil
+	case entity.FieldTypeInt8:
+		return entity.NewColumnInt8(field.Name, typed[int8](values, func(x interface{}) int8 { return int8(x.(int64)) })), nil
+	case entity.FieldTypeInt16:
+		return entity.NewColumnInt16(field.Name, typed[int16](values, func(x interface{}) int16 { return int16(x.(int64)) })), nil
+	case entity.FieldTypeInt32:
+		return entity.NewColumnInt32(field.Name, typed[int32](values, func(x interface{}) int32 { return int32(x.(int64)) })), nil
+	case entity.FieldTypeInt64:
+		return entity.NewColumnInt64(field.Name, typed[int64](values, func(x interface{}) int64 { return x.(int64) })), nil
+	case entity.FieldTypeFloat:
+		return entity.NewColumnFloat(field.Name, typed[float32](values, func(x interface{}) float32 { return float32(x.(float64)) })), nil
+	case entity.FieldTypeDouble:
+		return entity.NewColumnDouble(field.Name, typed[float64](values, func(x interface{}) float64 { return x.(float64) })), nil
+	case entity.FieldTypeVarChar, entity.FieldTypeString:
+		return entity.NewColumnVarChar(field.Name, typed[string](values, func(x interface{}) string { return x.(string) })), nil
+	case entity.FieldTypeFloatVector:
+		return entity.NewColumnFloatVector(field.Name, dim, typed[[]float32](values, func(x interface{}) []float32 { return x.([]float32) })), nil
+	case entity.FieldTypeFloat16Vector:
+		return entity.NewColumnFloat16Vector(field.Name, dim, typed[[]byte](values, func(x interface{}) []byte { return Float32ToFloat16(x.([]float32)) })), nil
+	case entity.FieldTypeBFloat16Vector:
+		return entity.NewColumnBFloat16Vector(field.Name, dim, typed[[]byte](values, func(x interface{}) []byte { return Float32ToBFloat16(x.([]float32)) })), nil
+	case entity.FieldTypeBinaryVector:
+		return entity.NewColumnBinaryVector(field.Name, dim, typed[[]byte](values, func(x interface{}) []byte { return x.([]byte) })), nil
+	}
+	return nil, fmt.Errorf("field %s of type %s cannot be imported: %w", field.Name, field.DataType.Name(), ErrInvalidArgument)
+}
+
+func typed[T any](values []interface{}, convert func(interface{}) T) []T {
+	data := make([]T, len(values))
+	for i, x := range values {
+		data[i] = convert(x)
+	}
+	return data
+}
@@ -0,0 +1,115 @@
+package qmilvus
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestFormatRoundTrip writes rows as Export does and reads them back as Import does, in every format
+func TestFormatRoundTrip(t *testing.T) {
+	c := NewCollection[*Article](milvusAdress)
+	in := []*Article{
+		{Id: 1, Title: "a, \"quoted\"\ntitle", TitleVec: []float32{0.1, 0.2, 0.3, 0.4}, BodyVec: []float32{0.5, 1, -2, 0, 0.25, 3, 4, 8}, Hash: []byte{1, 255}},
+		{Id: 2, Title: "", TitleVec: []float32{1, 2, 3, 4}, BodyVec: make([]float32, 8), Hash: []byte{0, 7}},
+	}
+	columns, err := c.BuildColumns(in...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []Format{FormatJSONL, FormatJSON, FormatCSV, FormatParquet} {
+		var buf bytes.Buffer
+		w, err := newRecordWriter(&buf, format, c.schemaIn.Fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range in {
+			row := map[string]interface{}{}
+			for _, column := range columns {
+				if row[column.Name()], err = exportValue(column, i); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err = w.Write(row); err != nil {
+				t.Fatalf("%s: %v", format, err)
+			}
+		}
+		if err = w.Close(); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		r, err := newRecordReader(bytes.NewReader(buf.Bytes()), format, c.schemaIn.Fields)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		var rows []map[string]interface{}
+		for {
+			row, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", format, err)
+			}
+			rows = append(rows, row)
+		}
+		out, err := c.importModels(rows, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if !reflect.DeepEqual(out, in) {
+			t.Errorf("%s: round trip got %+v %+v", format, out[0], out[1])
+		}
+
+		rows[1]["Unknown"] = "x"
+		if _, err = c.importModels(rows, 0); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: unknown field should be rejected, got %v", format, err)
+		}
+		delete(rows[1], "Unknown")
+		// a missing field is rejected whether other rows of the batch have it or not
+		delete(rows[1], "TitleVec")
+		if _, err = c.importModels(rows, 0); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: missing field should be rejected, got %v", format, err)
+		}
+		delete(rows[0], "TitleVec")
+		if _, err = c.importModels(rows, 0); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: field missing from the whole batch should be rejected, got %v", format, err)
+		}
+	}
+	if _, err := newRecordWriter(io.Discard, "xml", c.schemaIn.Fields); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("unknown format should be rejected, got %v", err)
+	}
+}
+
+// TestImportDropped imports rows dropped by the validation: the violations are numbered from the first row of the file
+func TestImportDropped(t *testing.T) {
+	c := NewCollection[*Article](milvusAdress).WithValidation(ValidateDrop)
+	in := make([]*Article, importBatchSize+2)
+	for i := range in {
+		in[i] = &Article{Id: int64(i), Title: strings.Repeat("t", 300), TitleVec: []float32{1, 0, 0, 0}, BodyVec: []float32{1, 0, 0, 0, 0, 0, 0, 0}, Hash: []byte{1, 2}}
+	}
+	columns, err := c.BuildColumns(in...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w, _ := newRecordWriter(&buf, FormatJSONL, c.schemaIn.Fields)
+	for i := range in {
+		row := map[string]interface{}{}
+		for _, column := range columns {
+			row[column.Name()], _ = exportValue(column, i)
+		}
+		w.Write(row)
+	}
+	w.Close()
+
+	var verr *ValidationError
+	n, err := c.Import(context.Background(), &buf, FormatJSONL)
+	if !errors.As(err, &verr) || !verr.Dropped || len(verr.Violations) != len(in) || verr.Violations[len(in)-1].Row != len(in)-1 || n != 0 {
+		t.Errorf("expected the dropped rows, got %d, %v", n, err)
+	}
+}
@@ -1,5 +1,11 @@
 package qmilvus
 
+import (
+	"fmt"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
 type IndexName string
 
 const (
@@ -13,3 +19,48 @@ const (
 	IndexBinFlat    IndexName = "BIN_FLAT"
 	IndexAuto       IndexName = "AUTOINDEX"
 )
+
+// NewIndex builds the index n with metric, nlist is the cluster count of the IVF indexes.
+// BIN_FLAT and BIN_IVFFLAT index binary vectors, by the HAMMING or JACCARD metric, the others float vectors.
+// i.g. qmilvus.IndexBinIvfFlat.NewIndex(entity.HAMMING, 128)
+func (n IndexName) NewIndex(metric entity.MetricType, nlist int) (entity.Index, error) {
+	if binary := n == IndexBinFlat || n == IndexBinIvfFlat; n != IndexAuto && binary != isBinaryMetric(metric) {
+		return nil, fmt.Errorf("metric %s does not fit index %s: %w", metric, n, ErrInvalidArgument)
+	}
+	switch n {
+	case IndexFlat:
+		return entity.NewIndexFlat(metric)
+	case IndexIvfFlat:
+		return entity.NewIndexIvfFlat(metric, nlist)
+	case IndexIvfSQ8:
+		return entity.NewIndexIvfSQ8(metric, nlist)
+	case IndexHNSW:
+		return entity.NewIndexHNSW(metric, 16, 200)
+	case IndexBinFlat:
+		return entity.NewIndexBinFlat(metric, nlist)
+	case IndexBinIvfFlat:
+		return entity.NewIndexBinIvfFlat(metric, nlist)
+	case IndexAuto:
+		return entity.NewIndexAUTOINDEX(metric)
+	}
+	// IVFPQ needs m and nbits, ANNOY is gone since milvus 2.3
+	return nil, fmt.Errorf("index %s cannot be built by NewIndex, use the entity.NewIndex funcs: %w", n, ErrInvalidArgument)
+}
+
+// DefaultIndex returns the index Create builds on a vector field when none is set:
+// AUTOINDEX by L2, BIN_FLAT by HAMMING for binary vectors
+func DefaultIndex(field *entity.Field) (entity.Index, error) {
+	if field.DataType == entity.FieldTypeBinaryVector {
+		return IndexBinFlat.NewIndex(entity.HAMMING, 1)
+	}
+	return IndexAuto.NewIndex(entity.L2, 0)
+}
+
+// isBinaryMetric reports whether metric compares binary vectors
+func isBinaryMetric(metric entity.MetricType) bool {
+	switch metric {
+	case entity.HAMMING, entity.JACCARD, entity.TANIMOTO, entity.SUBSTRUCTURE, entity.SUPERSTRUCTURE:
+		return true
+	}
+	return false
+}
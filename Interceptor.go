@@ -0,0 +1,72 @@
+package qmilvus
+
+import (
+	"context"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// operations seen by interceptors, in Request.Op
+const (
+	OpUpsert = "upsert"
+	OpInsert = "insert"
+	OpRemove = "remove"
+	OpSearch = "search"
+	OpQuery  = "query"
+	OpCount  = "count"
+	OpExport = "export"
+)
+
+// Request is the typed request of an intercepted operation, only the fields of its Op are set.
+// interceptors may modify it before calling next
+type Request[v any] struct {
+	Op string
+
+	// upsert, insert, remove: the models written or removed
+	Models []v
+	// remove: primary keys removed, set by RemoveByKeysI64/RemoveByKeysString
+	Keys entity.Column
+	// remove: rows matching Expression are removed, ANDed with Models/Keys when they are set.
+	// count, export: the rows counted or exported, all rows if empty
+	Expression string
+
+	// search
+	VectorField string
+	Vectors     []entity.Vector
+	Search      *SearchParams
+
+	// query
+	Query *QueryParams
+}
+
+// Response is the typed response of an intercepted operation, nil for writes
+type Response[v any] struct {
+	Models [][]v       // search: the hits of each query vector; query: Models[0] holds the rows
+	Scores [][]float32 // search: the scores of the hits
+	Count  int64       // count: the rows matching; export: the rows written
+}
+
+// Handler executes a request
+type Handler[v any] func(ctx context.Context, req *Request[v]) (*Response[v], error)
+
+// Interceptor wraps the operations of a collection. it calls next to go on, possibly with a modified request,
+// or returns its own response to short-circuit the operation
+type Interceptor[v any] func(ctx context.Context, req *Request[v], next Handler[v]) (*Response[v], error)
+
+// Use appends interceptors to the chain, the first registered is the outermost
+func (collection *Collection[v]) Use(interceptors ...Interceptor[v]) (ret *Collection[v]) {
+	collection.interceptors = append(collection.interceptors, interceptors...)
+	return collection
+}
+
+// intercept runs req through the interceptor chain, handler executing it at the end
+func (c *Collection[v]) intercept(ctx context.Context, req *Request[v], handler Handler[v]) (*Response[v], error) {
+	h := handler
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := c.interceptors[i], h
+		h = func(ctx context.Context, req *Request[v]) (*Response[v], error) {
+			return interceptor(ctx, req, next)
+		}
+	}
+	return h(ctx, req)
+}
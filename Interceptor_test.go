@@ -0,0 +1,100 @@
+package qmilvus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+func TestInterceptorChain(t *testing.T) {
+	var order []string
+	trace := func(name string) Interceptor[*ValidatedDoc] {
+		return func(ctx context.Context, req *Request[*ValidatedDoc], next Handler[*ValidatedDoc]) (*Response[*ValidatedDoc], error) {
+			order = append(order, name+" "+req.Op)
+			return next(ctx, req)
+		}
+	}
+	cached := []*ValidatedDoc{{Id: 7}}
+	c := NewCollection[*ValidatedDoc](milvusAdress).Use(trace("outer"), trace("inner"),
+		// serves searches from a cache, milvus is never called
+		func(ctx context.Context, req *Request[*ValidatedDoc], next Handler[*ValidatedDoc]) (*Response[*ValidatedDoc], error) {
+			if req.Op == OpSearch {
+				return &Response[*ValidatedDoc]{Models: [][]*ValidatedDoc{cached}, Scores: [][]float32{{0.5}}}, nil
+			}
+			return next(ctx, req)
+		})
+
+	models, scores, err := c.SearchVector([]float32{1, 0, 0, 0}, nil)
+	if err != nil || len(models) != 1 || models[0].Id != 7 || scores[0] != 0.5 {
+		t.Fatalf("expected the cached hit, got %v %v %v", models, scores, err)
+	}
+	if want := []string{"outer search", "inner search"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("expected %v, got %v", want, order)
+	}
+}
+
+func TestInterceptorRewritesRequest(t *testing.T) {
+	c := NewCollection[*ValidatedDoc](milvusAdress).Use(
+		func(ctx context.Context, req *Request[*ValidatedDoc], next Handler[*ValidatedDoc]) (*Response[*ValidatedDoc], error) {
+			// drop nil models before they reach the handler
+			var valid []*ValidatedDoc
+			for _, m := range req.Models {
+				if m != nil {
+					valid = append(valid, m)
+				}
+			}
+			req.Models = valid
+			if len(req.Models) == 0 {
+				return nil, errors.New("nothing to write")
+			}
+			return next(ctx, req)
+		})
+	if err := c.Upsert(nil, nil); err == nil || err.Error() != "nothing to write" {
+		t.Errorf("expected the interceptor error, got %v", err)
+	}
+}
+
+func TestInterceptedOps(t *testing.T) {
+	var ops []string
+	c := NewCollection[*Article](milvusAdress).Use(
+		func(ctx context.Context, req *Request[*Article], next Handler[*Article]) (*Response[*Article], error) {
+			ops = append(ops, req.Op)
+			if req.Op == OpCount || req.Op == OpExport {
+				return &Response[*Article]{Count: 42}, nil
+			}
+			return next(ctx, req)
+		})
+	ctx := context.Background()
+	if n, err := c.Count(ctx, ""); n != 42 || err != nil {
+		t.Errorf("expected the count of the interceptor, got %d %v", n, err)
+	}
+	if n, err := c.Export(ctx, io.Discard, FormatJSONL, ""); n != 42 || err != nil {
+		t.Errorf("expected the export of the interceptor, got %d %v", n, err)
+	}
+	w, _ := c.NewBulkWriter(DirStore{Dir: t.TempDir()}, "bulk", FormatJSON)
+	defer w.discard()
+	if err := w.Write(ctx, &Article{Id: 1, TitleVec: []float32{1, 0, 0, 0}, BodyVec: []float32{1, 0, 0, 0, 0, 0, 0, 0}, Hash: []byte{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{OpCount, OpExport, OpInsert}; !reflect.DeepEqual(ops, want) {
+		t.Errorf("expected %v, got %v", want, ops)
+	}
+}
+
+func TestPkInExpression(t *testing.T) {
+	c := NewCollection[*ValidatedDoc](milvusAdress)
+	ids, err := c.pkColumn([]*ValidatedDoc{{Id: 1}, {Id: 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expr, _ := pkInExpression(ids); expr != "Id in [1,2]" {
+		t.Errorf("unexpected expression %s", expr)
+	}
+	if expr, _ := pkInExpression(entity.NewColumnVarChar("Key", []string{`a"b`})); expr != `Key in ["a\"b"]` {
+		t.Errorf("unexpected expression %s", expr)
+	}
+}
@@ -0,0 +1,44 @@
+package qmilvus
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ObjectStore stores the files of bulk inserts where milvus reads them, i.e. the bucket of its minio or s3.
+// keys are slash separated paths from the root of the bucket, as passed to milvus bulk import
+type ObjectStore interface {
+	// Put stores the size bytes of r as the object key, replacing any object of that key
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+}
+
+// DirStore is an ObjectStore keeping objects as files under Dir,
+// for tests or for a milvus whose storage is a local directory, e.g. a volume mounted into the milvus container
+type DirStore struct {
+	Dir string
+}
+
+func (s DirStore) Put(ctx context.Context, key string, r io.Reader, size int64) (err error) {
+	path := filepath.Join(s.Dir, filepath.FromSlash(key))
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// written aside then renamed, milvus never reads a partial file
+	f, err := os.CreateTemp(filepath.Dir(path), ".put-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = io.CopyN(f, r, size); err == nil {
+		err = ctx.Err()
+	}
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
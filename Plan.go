@@ -0,0 +1,479 @@
+package qmilvus
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// modelPlan is the reflective access to the models of type v, compiled once by NewCollection.
+// the read and write paths look fields up by index through it, never by name
+type modelPlan struct {
+	structType reflect.Type
+	ptr        bool                  // v is a pointer to the struct
+	fields     map[string]*fieldPlan // every tagged field, by milvus field name
+	in         []*fieldPlan          // the fields written, in the order of the schema
+
+	// leaves are the struct fields stored in a column or untagged, by flattened name, their Index the path from the model
+	leaves map[string]reflect.StructField
+}
+
+// fieldPlan is the compiled access to one tagged field of the model
+type fieldPlan struct {
+	name   string // milvus field name
+	index  []int  // see reflect.Value.FieldByIndex
+	goType reflect.Type
+	field  *entity.Field
+	dim    int // vectors only
+
+	unit   time.Duration // time.Time fields, stored as epochs of unit
+	custom *fieldType    // registered with RegisterFieldType
+
+	// build makes the column of the field from the rows
+	build func(rows []reflect.Value) (entity.Column, error)
+}
+
+func newModelPlan(structType reflect.Type, ptr bool) *modelPlan {
+	return &modelPlan{structType: structType, ptr: ptr, fields: map[string]*fieldPlan{}, leaves: map[string]reflect.StructField{}}
+}
+
+// add compiles the access to a tagged field
+func (p *modelPlan) add(name string, index []int, spec *FieldSpec) {
+	fp := &fieldPlan{name: name, index: index, goType: p.structType.FieldByIndex(index).Type, field: spec.Field}
+	fp.dim, _ = strconv.Atoi(spec.Field.TypeParams[entity.TypeParamDim])
+	fp.unit, fp.custom = spec.Unit, lookupFieldType(fp.goType)
+	fp.build = compileBuild(fp)
+	p.fields[fp.name] = fp
+	if spec.In {
+		p.in = append(p.in, fp)
+	}
+}
+
+// rows returns the addressable struct values of models, the zero Value for nil models
+func (p *modelPlan) rows(models reflect.Value) []reflect.Value {
+	rows := make([]reflect.Value, models.Len())
+	for i := range rows {
+		row := models.Index(i)
+		for (row.Kind() == reflect.Ptr || row.Kind() == reflect.Interface) && !row.IsNil() {
+			row = row.Elem()
+		}
+		if row.Kind() == reflect.Struct {
+			rows[i] = row
+		}
+	}
+	return rows
+}
+
+// newModels allocates count zero models, their structs in one backing array, and returns them with their rows
+func (p *modelPlan) newModels(models reflect.Value) (rows []reflect.Value) {
+	rows = make([]reflect.Value, models.Len())
+	if !p.ptr {
+		for i := range rows {
+			rows[i] = models.Index(i)
+		}
+		return rows
+	}
+	backing := reflect.MakeSlice(reflect.SliceOf(p.structType), len(rows), len(rows))
+	for i := range rows {
+		rows[i] = backing.Index(i)
+		models.Index(i).Set(rows[i].Addr())
+	}
+	return rows
+}
+
+// index returns the index of the struct field name, for the untagged fields too
+func (p *modelPlan) index(name string) []int {
+	if fp, ok := p.fields[name]; ok {
+		return fp.index
+	}
+	return p.leaves[name].Index
+}
+
+// value returns the field of row, the zero value when a nested struct pointer on the way is nil
+func (fp *fieldPlan) value(row reflect.Value) reflect.Value {
+	if len(fp.index) == 1 {
+		return row.Field(fp.index[0])
+	}
+	for i, x := range fp.index {
+		if i > 0 && row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				return reflect.Zero(fp.goType)
+			}
+			row = row.Elem()
+		}
+		row = row.Field(x)
+	}
+	return row
+}
+
+// settable returns the field of row, allocating the nil nested struct pointers on the way
+func (fp *fieldPlan) settable(row reflect.Value) reflect.Value {
+	if len(fp.index) == 1 {
+		return row.Field(fp.index[0])
+	}
+	for i, x := range fp.index {
+		if i > 0 && row.Kind() == reflect.Ptr {
+			if row.IsNil() {
+				row.Set(reflect.New(row.Type().Elem()))
+			}
+			row = row.Elem()
+		}
+		row = row.Field(x)
+	}
+	return row
+}
+
+// compileBuild returns the column builder of a field, by its milvus type
+func compileBuild(fp *fieldPlan) func(rows []reflect.Value) (entity.Column, error) {
+	switch fp.field.DataType {
+	case entity.FieldTypeBool:
+		return buildScalar(fp, getBool(fp), func(name string, data []bool) entity.Column { return entity.NewColumnBool(name, data) })
+	case entity.FieldTypeInt8:
+		return buildScalar(fp, getInt[int8](fp), func(name string, data []int8) entity.Column { return entity.NewColumnInt8(name, data) })
+	case entity.FieldTypeInt16:
+		return buildScalar(fp, getInt[int16](fp), func(name string, data []int16) entity.Column { return entity.NewColumnInt16(name, data) })
+	case entity.FieldTypeInt32:
+		return buildScalar(fp, getInt[int32](fp), func(name string, data []int32) entity.Column { return entity.NewColumnInt32(name, data) })
+	case entity.FieldTypeInt64:
+		return buildScalar(fp, getInt[int64](fp), func(name string, data []int64) entity.Column { return entity.NewColumnInt64(name, data) })
+	case entity.FieldTypeFloat:
+		return buildScalar(fp, getFloat[float32](fp), func(name string, data []float32) entity.Column { return entity.NewColumnFloat(name, data) })
+	case entity.FieldTypeDouble:
+		return buildScalar(fp, getFloat[float64](fp), func(name string, data []float64) entity.Column { return entity.NewColumnDouble(name, data) })
+	case entity.FieldTypeVarChar, entity.FieldTypeString:
+		return buildScalar(fp, getString(fp), func(name string, data []string) entity.Column { return entity.NewColumnVarChar(name, data) })
+	case entity.FieldTypeFloatVector:
+		return func(rows []reflect.Value) (entity.Column, error) {
+			data := make([][]float32, len(rows))
+			for i, row := range rows {
+				var vector []float32
+				switch f := fp.value(row); {
+				case fp.goType == float32sType && f.CanAddr():
+					// through a pointer, boxing the slice header would allocate
+					vector = *f.Addr().Interface().(*[]float32)
+				case fp.goType.Elem().Kind() == reflect.Float64:
+					vector = Float32s(f.Convert(float64sType).Interface().([]float64))
+				default:
+					vector = f.Convert(float32sType).Interface().([]float32)
+				}
+				if len(vector) != fp.dim {
+					return nil, &ErrDimMismatch{Field: fp.name, Want: fp.dim, Got: len(vector), Row: i}
+				}
+				data[i] = vector
+			}
+			return entity.NewColumnFloatVector(fp.name, fp.dim, data), nil
+		}
+	case entity.FieldTypeFloat16Vector:
+		return buildHalf(fp, Float32ToFloat16, func(name string, dim int, data [][]byte) entity.Column {
+			return entity.NewColumnFloat16Vector(name, dim, data)
+		})
+	case entity.FieldTypeBFloat16Vector:
+		return buildHalf(fp, Float32ToBFloat16, func(name string, dim int, data [][]byte) entity.Column {
+			return entity.NewColumnBFloat16Vector(name, dim, data)
+		})
+	case entity.FieldTypeBinaryVector:
+		return func(rows []reflect.Value) (entity.Column, error) {
+			if fp.goType.Kind() != reflect.Slice || fp.goType.Elem().Kind() != reflect.Uint8 {
+				return nil, fmt.Errorf("field %s: binary vector of type %s, should be []byte: %w", fp.name, fp.goType, ErrInvalidArgument)
+			}
+			data := make([][]byte, len(rows))
+			for i, row := range rows {
+				// binary vector dim counts bits
+				if vector := fp.value(row).Bytes(); len(vector) != fp.dim/8 {
+					return nil, &ErrDimMismatch{Field: fp.name, Want: fp.dim / 8, Got: len(vector), Row: i}
+				} else {
+					data[i] = vector
+				}
+			}
+			return entity.NewColumnBinaryVector(fp.name, fp.dim, data), nil
+		}
+	}
+	return unsupported(fp)
+}
+
+// buildHalf returns the builder of a float16 or bfloat16 vector column, 2 bytes per dim,
+// from []float32 or []float64 fields converted by encode, []uint16 bits or raw bytes
+func buildHalf(fp *fieldPlan, encode func([]float32) []byte, newColumn func(name string, dim int, data [][]byte) entity.Column) func(rows []reflect.Value) (entity.Column, error) {
+	var (
+		elem   = fp.goType.Elem().Kind()
+		length = fp.dim
+		bytes  func(f reflect.Value) []byte
+	)
+	switch elem {
+	case reflect.Float32:
+		bytes = func(f reflect.Value) []byte { return encode(f.Convert(float32sType).Interface().([]float32)) }
+	case reflect.Float64:
+		bytes = func(f reflect.Value) []byte { return encode(Float32s(f.Convert(float64sType).Interface().([]float64))) }
+	case reflect.Uint16:
+		bytes = func(f reflect.Value) []byte { return Uint16sToBytes(f.Convert(uint16sType).Interface().([]uint16)) }
+	case reflect.Uint8:
+		bytes, length = reflect.Value.Bytes, 2*fp.dim
+	}
+	return func(rows []reflect.Value) (entity.Column, error) {
+		data := make([][]byte, len(rows))
+		for i, row := range rows {
+			f := fp.value(row)
+			if f.Len() != length {
+				return nil, &ErrDimMismatch{Field: fp.name, Want: length, Got: f.Len(), Row: i}
+			}
+			data[i] = bytes(f)
+		}
+		return newColumn(fp.name, fp.dim, data), nil
+	}
+}
+
+// setHalf sets the fields of rows from the float16 or bfloat16 vectors data, decoded to floats by decode
+func (fp *fieldPlan) setHalf(rows []reflect.Value, data [][]byte, decode func([]byte) []float32) error {
+	for i, x := range data {
+		var value interface{}
+		switch fp.goType.Elem().Kind() {
+		case reflect.Float32:
+			value = decode(x)
+		case reflect.Float64:
+			value = Float64s(decode(x))
+		case reflect.Uint16:
+			value = BytesToUint16s(x)
+		case reflect.Uint8:
+			value = x
+		}
+		fp.settable(rows[i]).Set(reflect.ValueOf(value).Convert(fp.goType))
+	}
+	return nil
+}
+
+func unsupported(fp *fieldPlan) func(rows []reflect.Value) (entity.Column, error) {
+	return func(rows []reflect.Value) (entity.Column, error) {
+		return nil, fmt.Errorf("field %s: unsupported data type %v: %w", fp.name, fp.field.DataType, ErrInvalidArgument)
+	}
+}
+
+// buildScalar returns the builder of a scalar column, get reads the value of one row
+func buildScalar[T any](fp *fieldPlan, get func(reflect.Value) (T, error), newColumn func(name string, data []T) entity.Column) func(rows []reflect.Value) (entity.Column, error) {
+	if get == nil {
+		return unsupported(fp)
+	}
+	return func(rows []reflect.Value) (entity.Column, error) {
+		data := make([]T, len(rows))
+		for i, row := range rows {
+			x, err := get(fp.value(row))
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", i, err)
+			}
+			data[i] = x
+		}
+		return newColumn(fp.name, data), nil
+	}
+}
+
+// getInt returns the reader of the field as a value of its int column: ints, uints checked for overflow,
+// time.Time as epoch and registered types. nil when the field cannot be read so
+func getInt[T int8 | int16 | int32 | int64](fp *fieldPlan) func(reflect.Value) (T, error) {
+	switch kind := fp.goType.Kind(); {
+	case fp.custom != nil:
+		get, _ := fp.custom.get.(func(reflect.Value) (T, error))
+		return get
+	case fp.goType == timeType:
+		return func(f reflect.Value) (T, error) {
+			if f.CanAddr() {
+				return T(TimeToEpoch(*f.Addr().Interface().(*time.Time), fp.unit)), nil
+			}
+			return T(TimeToEpoch(f.Interface().(time.Time), fp.unit)), nil
+		}
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		return func(f reflect.Value) (T, error) { return T(f.Int()), nil }
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		return func(f reflect.Value) (T, error) {
+			x := f.Uint()
+			if T(x) < 0 || uint64(T(x)) != x {
+				return 0, overflows(fp, x)
+			}
+			return T(x), nil
+		}
+	}
+	return nil
+}
+
+func getFloat[T float32 | float64](fp *fieldPlan) func(reflect.Value) (T, error) {
+	switch kind := fp.goType.Kind(); {
+	case fp.custom != nil:
+		get, _ := fp.custom.get.(func(reflect.Value) (T, error))
+		return get
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		return func(f reflect.Value) (T, error) { return T(f.Float()), nil }
+	}
+	return nil
+}
+
+func getString(fp *fieldPlan) func(reflect.Value) (string, error) {
+	switch {
+	case fp.custom != nil:
+		get, _ := fp.custom.get.(func(reflect.Value) (string, error))
+		return get
+	case fp.goType.Kind() == reflect.String:
+		return func(f reflect.Value) (string, error) { return f.String(), nil }
+	}
+	return nil
+}
+
+func getBool(fp *fieldPlan) func(reflect.Value) (bool, error) {
+	switch {
+	case fp.custom != nil:
+		get, _ := fp.custom.get.(func(reflect.Value) (bool, error))
+		return get
+	case fp.goType.Kind() == reflect.Bool:
+		return func(f reflect.Value) (bool, error) { return f.Bool(), nil }
+	}
+	return nil
+}
+
+// set fills the field of rows from column, converting between the widths of ints and floats
+func (fp *fieldPlan) set(rows []reflect.Value, column entity.Column) error {
+	switch col := column.(type) {
+	case *entity.ColumnBool:
+		return setScalar(fp, rows, col.Data(), setBool(fp))
+	case *entity.ColumnInt8:
+		return setScalar(fp, rows, col.Data(), setInt[int8](fp))
+	case *entity.ColumnInt16:
+		return setScalar(fp, rows, col.Data(), setInt[int16](fp))
+	case *entity.ColumnInt32:
+		return setScalar(fp, rows, col.Data(), setInt[int32](fp))
+	case *entity.ColumnInt64:
+		return setScalar(fp, rows, col.Data(), setInt[int64](fp))
+	case *entity.ColumnFloat:
+		return setScalar(fp, rows, col.Data(), setFloat[float32](fp))
+	case *entity.ColumnDouble:
+		return setScalar(fp, rows, col.Data(), setFloat[float64](fp))
+	case *entity.ColumnVarChar:
+		return setScalar(fp, rows, col.Data(), setString(fp))
+	case *entity.ColumnString:
+		return setScalar(fp, rows, col.Data(), setString(fp))
+	case *entity.ColumnFloatVector:
+		switch {
+		case fp.goType == float32sType:
+			for i, x := range col.Data() {
+				*fp.settable(rows[i]).Addr().Interface().(*[]float32) = x
+			}
+		case fp.goType.Kind() == reflect.Slice && fp.goType.Elem().Kind() == reflect.Float32:
+			for i, x := range col.Data() {
+				fp.settable(rows[i]).Set(reflect.ValueOf(x).Convert(fp.goType))
+			}
+		case fp.goType.Kind() == reflect.Slice && fp.goType.Elem().Kind() == reflect.Float64:
+			for i, x := range col.Data() {
+				fp.settable(rows[i]).Set(reflect.ValueOf(Float64s(x)).Convert(fp.goType))
+			}
+		default:
+			return fp.mismatch(column)
+		}
+		return nil
+	case *entity.ColumnFloat16Vector:
+		if fp.field.DataType != entity.FieldTypeFloat16Vector {
+			return fp.mismatch(column)
+		}
+		return fp.setHalf(rows, col.Data(), Float16ToFloat32)
+	case *entity.ColumnBFloat16Vector:
+		if fp.field.DataType != entity.FieldTypeBFloat16Vector {
+			return fp.mismatch(column)
+		}
+		return fp.setHalf(rows, col.Data(), BFloat16ToFloat32)
+	case *entity.ColumnBinaryVector:
+		if fp.goType.Kind() != reflect.Slice || fp.goType.Elem().Kind() != reflect.Uint8 {
+			return fp.mismatch(column)
+		}
+		return setScalar(fp, rows, col.Data(), infallible(reflect.Value.SetBytes))
+	}
+	return fmt.Errorf("unsupported column type %T for column '%s'", column, column.Name())
+}
+
+func (fp *fieldPlan) mismatch(column entity.Column) error {
+	return fmt.Errorf("type mismatch for field '%s': expected %s, got %s from Milvus", fp.name, fp.goType, column.Type().Name())
+}
+
+// setScalar sets the field of every row from data, set is nil when the field cannot hold the column values
+func setScalar[T any](fp *fieldPlan, rows []reflect.Value, data []T, set func(reflect.Value, T) error) error {
+	if set == nil {
+		return fmt.Errorf("type mismatch for field '%s': expected %s, got %T from Milvus", fp.name, fp.goType, data)
+	}
+	for i, x := range data {
+		if err := set(fp.settable(rows[i]), x); err != nil {
+			return fmt.Errorf("row %d field %s: %w", i, fp.name, err)
+		}
+	}
+	return nil
+}
+
+func infallible[T any](set func(reflect.Value, T)) func(reflect.Value, T) error {
+	return func(f reflect.Value, x T) error {
+		set(f, x)
+		return nil
+	}
+}
+
+// setInt returns the setter of the field from the values of an int column, of any width
+func setInt[T int8 | int16 | int32 | int64](fp *fieldPlan) func(reflect.Value, T) error {
+	switch kind := fp.goType.Kind(); {
+	case fp.custom != nil:
+		set, _ := fp.custom.set.(func(reflect.Value, T) error)
+		return set
+	case fp.goType == timeType:
+		return func(f reflect.Value, x T) error {
+			*f.Addr().Interface().(*time.Time) = EpochToTime(int64(x), fp.unit)
+			return nil
+		}
+	case kind >= reflect.Int && kind <= reflect.Int64:
+		return func(f reflect.Value, x T) error {
+			if f.OverflowInt(int64(x)) {
+				return fmt.Errorf("%d overflows %s: %w", x, fp.goType, ErrInvalidArgument)
+			}
+			f.SetInt(int64(x))
+			return nil
+		}
+	case kind >= reflect.Uint && kind <= reflect.Uint64:
+		return func(f reflect.Value, x T) error {
+			if x < 0 || f.OverflowUint(uint64(x)) {
+				return fmt.Errorf("%d overflows %s: %w", x, fp.goType, ErrInvalidArgument)
+			}
+			f.SetUint(uint64(x))
+			return nil
+		}
+	}
+	return nil
+}
+
+func setFloat[T float32 | float64](fp *fieldPlan) func(reflect.Value, T) error {
+	switch kind := fp.goType.Kind(); {
+	case fp.custom != nil:
+		set, _ := fp.custom.set.(func(reflect.Value, T) error)
+		return set
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		return func(f reflect.Value, x T) error {
+			f.SetFloat(float64(x))
+			return nil
+		}
+	}
+	return nil
+}
+
+func setString(fp *fieldPlan) func(reflect.Value, string) error {
+	switch {
+	case fp.custom != nil:
+		set, _ := fp.custom.set.(func(reflect.Value, string) error)
+		return set
+	case fp.goType.Kind() == reflect.String:
+		return infallible(reflect.Value.SetString)
+	}
+	return nil
+}
+
+func setBool(fp *fieldPlan) func(reflect.Value, bool) error {
+	switch {
+	case fp.custom != nil:
+		set, _ := fp.custom.set.(func(reflect.Value, bool) error)
+		return set
+	case fp.goType.Kind() == reflect.Bool:
+		return infallible(reflect.Value.SetBool)
+	}
+	return nil
+}
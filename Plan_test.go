@@ -0,0 +1,81 @@
+package qmilvus
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+)
+
+func planDocs(n int) []*ValidatedDoc {
+	docs := make([]*ValidatedDoc, n)
+	for i := range docs {
+		docs[i] = &ValidatedDoc{Id: int64(i), Title: "doc", Rank: float32(i), Vector: []float32{1, 0, 0, float32(i)}}
+	}
+	return docs
+}
+
+func TestPlanRoundTrip(t *testing.T) {
+	c := NewCollection[*ValidatedDoc](milvusAdress)
+	in := planDocs(8)
+	columns, err := c.BuildColumns(in...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := c.ParseSearchResult(&client.SearchResult{ResultCount: len(in), Fields: columns})
+	if err != nil || !reflect.DeepEqual(out, in) {
+		t.Fatalf("models differ after a round trip, %v", err)
+	}
+
+	// value models, hydrated in place
+	values := NewCollection[ValidatedDoc](milvusAdress)
+	hits, err := values.ParseSearchResult(&client.SearchResult{ResultCount: len(in), Fields: columns})
+	if err != nil || hits[3].Id != 3 || hits[3].Vector[3] != 3 {
+		t.Fatalf("unexpected value models %v, %v", hits, err)
+	}
+
+	if ids, err := c.pkColumn(in[:2]); err != nil || ids.Len() != 2 || ids.Name() != "Id" {
+		t.Errorf("unexpected pk column %v, %v", ids, err)
+	}
+}
+
+// the hot loops allocate per column, never per row
+func TestPlanAllocationFree(t *testing.T) {
+	c := NewCollection[*ValidatedDoc](milvusAdress)
+	small, large := planDocs(10), planDocs(1000)
+	columns, _ := c.BuildColumns(large...)
+	result := &client.SearchResult{ResultCount: len(large), Fields: columns}
+
+	build := func(docs []*ValidatedDoc) func() {
+		return func() { c.BuildColumns(docs...) }
+	}
+	if a, b := testing.AllocsPerRun(10, build(small)), testing.AllocsPerRun(10, build(large)); a != b {
+		t.Errorf("BuildColumns allocates per row: %v allocs for 10 rows, %v for 1000", a, b)
+	}
+	if allocs := testing.AllocsPerRun(10, func() { c.ParseSearchResult(result) }); allocs > 20 {
+		t.Errorf("ParseSearchResult allocates per row: %v allocs for 1000 rows", allocs)
+	}
+}
+
+func BenchmarkPlanBuildColumns(b *testing.B) {
+	c := NewCollection[*ValidatedDoc](milvusAdress)
+	docs := planDocs(1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.BuildColumns(docs...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPlanParseSearchResult(b *testing.B) {
+	c := NewCollection[*ValidatedDoc](milvusAdress)
+	columns, _ := c.BuildColumns(planDocs(1000)...)
+	result := &client.SearchResult{ResultCount: 1000, Fields: columns}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.ParseSearchResult(result); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
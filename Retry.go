@@ -0,0 +1,240 @@
+package qmilvus
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RetryRule retries errors of one class, matched with errors.Is, up to MaxAttempts attempts in total
+type RetryRule struct {
+	Class       error
+	MaxAttempts int
+}
+
+// RetryPolicy retries failed operations with exponential backoff and jitter.
+// only idempotent operations are retried: Upsert, Remove, searches, queries and admin calls, never Insert
+type RetryPolicy struct {
+	Rules          []RetryRule
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64 // the backoff is randomized by ±Jitter, 0.2 means ±20%
+}
+
+// DefaultRetryPolicy retries the errors expected during rolling restarts and load peaks
+var DefaultRetryPolicy = &RetryPolicy{
+	Rules: []RetryRule{
+		{Class: ErrUnavailable, MaxAttempts: 3},
+		{Class: ErrRateLimited, MaxAttempts: 5},
+		{Class: ErrNotLoaded, MaxAttempts: 3},
+	},
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// NoRetry disables retries
+var NoRetry = &RetryPolicy{}
+
+// maxAttempts returns how many attempts the policy allows for err
+func (p *RetryPolicy) maxAttempts(err error) int {
+	for _, rule := range p.Rules {
+		if errors.Is(err, rule.Class) {
+			return rule.MaxAttempts
+		}
+	}
+	return 1
+}
+
+// backoff returns the wait before the retry following attempt, attempt counting from 1
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	d *= 1 + p.Jitter*(2*rand.Float64()-1)
+	return time.Duration(d)
+}
+
+// ErrCircuitOpen is returned without calling milvus while the circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open, milvus considered down")
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// CircuitBreaker fails fast once FailureThreshold consecutive operations found milvus unavailable.
+// after OpenTimeout one probe operation is let through, its success closes the circuit again.
+// share one breaker between the collections of the same cluster with WithCircuitBreaker
+type CircuitBreaker struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{FailureThreshold: failureThreshold, OpenTimeout: openTimeout}
+}
+
+// allow reports whether an operation may call milvus
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case circuitOpen:
+		if time.Since(b.openedAt) < b.OpenTimeout {
+			return false
+		}
+		b.state, b.probing = circuitHalfOpen, true
+		return true
+	case circuitHalfOpen:
+		// one probe at a time
+		if b.probing {
+			return false
+		}
+		b.probing = true
+	}
+	return true
+}
+
+// record updates the breaker with the outcome of an operation, it reports whether the circuit just opened
+func (b *CircuitBreaker) record(err error) (opened bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if errors.Is(err, context.Canceled) {
+		// says nothing about milvus
+		return false
+	}
+	if !errors.Is(err, ErrUnavailable) {
+		// milvus answered, even if with an error
+		b.state, b.failures = circuitClosed, 0
+		return false
+	}
+	b.failures++
+	if b.state == circuitHalfOpen || (b.state == circuitClosed && b.failures >= b.FailureThreshold) {
+		b.state, b.openedAt = circuitOpen, time.Now()
+		return true
+	}
+	return false
+}
+
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state.String()
+}
+
+// RetryCounters is a snapshot of the retry and circuit breaker activity of a collection
+type RetryCounters struct {
+	Calls          int64 // operations started
+	Attempts       int64 // calls to milvus, retries included
+	Retries        int64
+	Failures       int64 // operations failed after their last attempt
+	ShortCircuited int64 // attempts rejected by the open circuit
+	CircuitOpened  int64
+	CircuitState   string
+}
+
+type retryCounters struct {
+	calls, attempts, retries, failures, shortCircuited, circuitOpened int64
+}
+
+// WithRetryPolicy sets the retry policy, NoRetry disables retries
+func (collection *Collection[v]) WithRetryPolicy(policy *RetryPolicy) (ret *Collection[v]) {
+	collection.retryPolicy = policy
+	return collection
+}
+
+// WithCircuitBreaker sets the circuit breaker, nil disables it
+func (collection *Collection[v]) WithCircuitBreaker(breaker *CircuitBreaker) (ret *Collection[v]) {
+	collection.breaker = breaker
+	return collection
+}
+
+// RetryCounters returns the retry and circuit breaker counters, for monitoring
+func (c *Collection[v]) RetryCounters() RetryCounters {
+	counters := RetryCounters{
+		Calls:          atomic.LoadInt64(&c.counters.calls),
+		Attempts:       atomic.LoadInt64(&c.counters.attempts),
+		Retries:        atomic.LoadInt64(&c.counters.retries),
+		Failures:       atomic.LoadInt64(&c.counters.failures),
+		ShortCircuited: atomic.LoadInt64(&c.counters.shortCircuited),
+		CircuitOpened:  atomic.LoadInt64(&c.counters.circuitOpened),
+		CircuitState:   circuitClosed.String(),
+	}
+	if c.breaker != nil {
+		counters.CircuitState = c.breaker.State()
+	}
+	return counters
+}
+
+// retry runs fn under the circuit breaker, retrying it by the retry policy when op is idempotent
+func (c *Collection[v]) retry(ctx context.Context, op *operation, fn func(ctx context.Context) error) (err error) {
+	atomic.AddInt64(&c.counters.calls, 1)
+	policy := c.retryPolicy
+	if policy == nil || !op.idempotent {
+		policy = NoRetry
+	}
+	for attempt := 1; ; attempt++ {
+		if c.breaker != nil && !c.breaker.allow() {
+			atomic.AddInt64(&c.counters.shortCircuited, 1)
+			err = ErrCircuitOpen
+		} else {
+			atomic.AddInt64(&c.counters.attempts, 1)
+			err = fn(ctx)
+			if c.breaker != nil && c.breaker.record(err) {
+				atomic.AddInt64(&c.counters.circuitOpened, 1)
+				c.logger.Error("milvus unavailable, circuit breaker opened", "address", c.milvusAddress, "collection", c.collectionName, "error", err)
+			}
+		}
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, ErrCircuitOpen) || attempt >= policy.maxAttempts(err) {
+			atomic.AddInt64(&c.counters.failures, 1)
+			return err
+		}
+
+		backoff := policy.backoff(attempt)
+		c.logger.Warn("milvus operation failed, retrying", "operation", op.name, "collection", c.collectionName, "attempt", attempt, "backoff", backoff, "error", err)
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(attribute.Int("attempt", attempt), attribute.String("error", err.Error())))
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			atomic.AddInt64(&c.counters.failures, 1)
+			return err
+		case <-timer.C:
+		}
+		atomic.AddInt64(&c.counters.retries, 1)
+	}
+}
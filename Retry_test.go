@@ -0,0 +1,67 @@
+package qmilvus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var fastRetry = &RetryPolicy{
+	Rules:          []RetryRule{{Class: ErrUnavailable, MaxAttempts: 3}},
+	InitialBackoff: time.Millisecond,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+func TestRetryIdempotency(t *testing.T) {
+	c := NewCollection[*OggAction](milvusAdress).WithRetryPolicy(fastRetry).WithCircuitBreaker(nil)
+	unavailable := &kindError{kind: ErrUnavailable, err: errors.New("connection refused")}
+
+	calls := 0
+	err := c.retry(context.Background(), &operation{idempotent: true}, func(context.Context) error {
+		if calls++; calls < 3 {
+			return unavailable
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("idempotent op should succeed on attempt 3, got %d calls, %v", calls, err)
+	}
+
+	calls = 0
+	err = c.retry(context.Background(), &operation{}, func(context.Context) error { calls++; return unavailable })
+	if !errors.Is(err, ErrUnavailable) || calls != 1 {
+		t.Errorf("non idempotent op should not be retried, got %d calls", calls)
+	}
+
+	calls = 0
+	_ = c.retry(context.Background(), &operation{idempotent: true}, func(context.Context) error { calls++; return ErrInvalidArgument })
+	if calls != 1 {
+		t.Errorf("errors without rule should not be retried, got %d calls", calls)
+	}
+	if counters := c.RetryCounters(); counters.Retries != 2 || counters.Failures != 2 {
+		t.Errorf("unexpected counters %+v", counters)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	breaker := NewCircuitBreaker(2, 20*time.Millisecond)
+	c := NewCollection[*OggAction](milvusAdress).WithRetryPolicy(NoRetry).WithCircuitBreaker(breaker)
+	down := func(context.Context) error { return &kindError{kind: ErrUnavailable, err: errors.New("down")} }
+
+	_ = c.retry(context.Background(), &operation{idempotent: true}, down)
+	_ = c.retry(context.Background(), &operation{idempotent: true}, down)
+	called := false
+	if err := c.retry(context.Background(), &operation{idempotent: true}, func(context.Context) error { called = true; return nil }); !errors.Is(err, ErrCircuitOpen) || called {
+		t.Fatalf("open circuit should fail fast, got %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if err := c.retry(context.Background(), &operation{idempotent: true}, func(context.Context) error { return nil }); err != nil {
+		t.Fatalf("probe after OpenTimeout should go through, got %v", err)
+	}
+	if counters := c.RetryCounters(); counters.CircuitState != "closed" || counters.CircuitOpened != 1 || counters.ShortCircuited != 1 {
+		t.Errorf("unexpected counters %+v", counters)
+	}
+}
@@ -0,0 +1,217 @@
+package qmilvus
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// StructField is a field of a model struct: its name, its go type and its tags.
+// the type is the underlying type of named types (int64 for time.Duration), time.Time is kept as it is.
+// NewCollection reads it by reflection, qmilvus-gen from source, both map it to milvus with SchemaField
+type StructField struct {
+	Name string
+	Type string
+	Tag  reflect.StructTag
+}
+
+// FieldSpec is the mapping of a struct field to milvus
+type FieldSpec struct {
+	Field *entity.Field
+	In    bool // written to milvus: tagged in, or the primary key
+	// Unit of the epochs time.Time fields are stored as, the unit= option
+	Unit time.Duration
+}
+
+// SchemaField returns the milvus mapping of a struct field, nil when the field has no milvus tag
+func SchemaField(f StructField) (spec *FieldSpec, err error) {
+	tagMilvus := strings.ToLower(f.Tag.Get("milvus"))
+	if tagMilvus == "" {
+		return nil, nil
+	}
+	spec = &FieldSpec{}
+	TypeParams := map[string]string{}
+	_fieldType := f.Type
+	//pointer fields and `nullable` would map to nullable fields, milvus-sdk-go v2.4 cannot write them
+	if strings.HasPrefix(_fieldType, "*") || tagFlag(tagMilvus, "nullable") {
+		return nil, fmt.Errorf("%s %s: unsupported: pointer fields need nullable support, which milvus-sdk-go v2.4 lacks", f.Name, f.Type)
+	}
+	var columeType entity.FieldType
+	//set `vector`, the element type of vectors: float32 by default, float16 or bfloat16 to halve their size
+	vector, _ := tagOption(tagMilvus, "vector")
+	switch vector {
+	case "", "float32", "binary":
+	case "float16", "bfloat16":
+		if columeType = entity.FieldTypeFloat16Vector; vector == "bfloat16" {
+			columeType = entity.FieldTypeBFloat16Vector
+		}
+		switch _fieldType {
+		case "[]float32", "[]float64", "[]uint16", "[]uint8", "[]byte":
+			// floats are converted, uint16 are the raw bits, bytes the raw little endian bits
+		default:
+			return nil, fmt.Errorf("%s: vector=%s needs a []float32, []float64, []uint16 or []byte field", f.Name, vector)
+		}
+		if TypeParams[entity.TypeParamDim], err = tagDim(f.Name, tagMilvus); err != nil {
+			return nil, err
+		}
+		_fieldType = ""
+	default:
+		return nil, fmt.Errorf("%s: vector=%s should be float32, float16, bfloat16 or binary", f.Name, vector)
+	}
+	switch _fieldType {
+	case "":
+		// half vectors, mapped above
+	case "int64", "int", "uint", "uint32", "uint64":
+		columeType = entity.FieldTypeInt64
+	case "int32", "uint16":
+		columeType = entity.FieldTypeInt32
+	case "int16", "uint8", "byte":
+		columeType = entity.FieldTypeInt16
+	case "int8":
+		columeType = entity.FieldTypeInt8
+	case "time.Time":
+		//stored as int64 epoch, `unit` is s, ms, us or ns, ms by default
+		columeType = entity.FieldTypeInt64
+		spec.Unit = time.Millisecond
+		if unit, ok := tagOption(tagMilvus, "unit"); ok {
+			if spec.Unit, ok = timeUnits[unit]; !ok {
+				return nil, fmt.Errorf("%s: unit=%s should be s, ms, us or ns", f.Name, unit)
+			}
+		}
+	case "string":
+		columeType = entity.FieldTypeVarChar
+		if TypeParams[entity.TypeParamMaxLength] = f.Tag.Get(entity.TypeParamMaxLength); TypeParams[entity.TypeParamMaxLength] == "" {
+			TypeParams[entity.TypeParamMaxLength], _ = tagOption(tagMilvus, entity.TypeParamMaxLength)
+		}
+		if TypeParams[entity.TypeParamMaxLength] == "" {
+			TypeParams[entity.TypeParamMaxLength] = "65535"
+		}
+	case "float32":
+		columeType = entity.FieldTypeFloat
+	case "float64":
+		columeType = entity.FieldTypeDouble
+	case "[]float32", "[]float64":
+		//[]float64 vectors are converted to float32
+		columeType = entity.FieldTypeFloatVector
+		//set `dim`  `max_capacity`
+		if TypeParams[entity.TypeParamDim], err = tagDim(f.Name, tagMilvus); err != nil {
+			return nil, err
+		}
+	case "bool":
+		columeType = entity.FieldTypeBool
+	case "[]byte", "[]uint8":
+		//dim counts bits, 8 per byte
+		columeType = entity.FieldTypeBinaryVector
+		if TypeParams[entity.TypeParamDim], err = tagDim(f.Name, tagMilvus); err != nil {
+			return nil, err
+		}
+		if dim, _ := strconv.Atoi(TypeParams[entity.TypeParamDim]); dim%8 != 0 {
+			return nil, fmt.Errorf("%s: dim=%d of a binary vector should be a multiple of 8", f.Name, dim)
+		}
+	default:
+		return nil, fmt.Errorf("PrimaryKey should be unique, with type int64 or string, unsupported type %s", f.Type)
+	}
+	_primarykey := strings.Contains(tagMilvus, "pk") && (columeType == entity.FieldTypeInt64 || columeType == entity.FieldTypeVarChar) && spec.Unit == 0
+	if TypeParams[entity.TypeParamDim] == "" {
+		delete(TypeParams, entity.TypeParamDim)
+	}
+	if vector == "float32" && columeType != entity.FieldTypeFloatVector {
+		return nil, fmt.Errorf("%s: vector=float32 needs a []float32 or []float64 field", f.Name)
+	}
+	if vector == "binary" && columeType != entity.FieldTypeBinaryVector {
+		return nil, fmt.Errorf("%s: vector=binary needs a []byte field", f.Name)
+	}
+
+	spec.Field = &entity.Field{Name: f.Name, DataType: columeType, PrimaryKey: _primarykey, AutoID: false, TypeParams: TypeParams}
+	//set `partition_key`
+	if tagFlag(tagMilvus, "partition_key") {
+		if columeType != entity.FieldTypeInt64 && columeType != entity.FieldTypeVarChar {
+			return nil, fmt.Errorf("partition key %s should be int64 or string", f.Name)
+		}
+		spec.Field.IsPartitionKey = true
+	}
+	spec.In = strings.Contains(tagMilvus, "in") || _primarykey
+	return spec, nil
+}
+
+// OutputField reports whether the field tagged tag is returned by searches and queries, the out option
+func OutputField(tag reflect.StructTag) bool {
+	tagMilvus := strings.ToLower(tag.Get("milvus"))
+	return strings.Contains(tagMilvus, "out") || strings.Contains(tagMilvus, "PK")
+}
+
+// isVector reports whether t is a vector type
+func isVector(t entity.FieldType) bool {
+	switch t {
+	case entity.FieldTypeFloatVector, entity.FieldTypeBinaryVector, entity.FieldTypeFloat16Vector, entity.FieldTypeBFloat16Vector:
+		return true
+	}
+	return false
+}
+
+// FlattenField reports whether the struct field f, of struct or pointer to struct type, is flattened into the columns of its parent,
+// and the prefix of the names of its columns. anonymous embedded structs are flattened as they are,
+// named nested structs need the prefix option, e.g. `milvus:"prefix=owner_"`, untagged ones are ignored
+func FlattenField(f StructField, anonymous bool) (prefix string, flatten bool, err error) {
+	tag := f.Tag.Get("milvus")
+	prefix, hasPrefix := tagOption(tag, "prefix")
+	if anonymous || hasPrefix {
+		return prefix, true, nil
+	}
+	if tag != "" {
+		return "", false, fmt.Errorf("nested struct %s needs a prefix for its columns, e.g. `milvus:\"prefix=%s_\"`", f.Name, strings.ToLower(f.Name))
+	}
+	return "", false, nil
+}
+
+// walkFields calls fn for every leaf field of structType, the fields of flattened structs included,
+// with its milvus name and its index path
+func walkFields(structType reflect.Type, fn func(name string, index []int, tpi reflect.StructField)) {
+	var walk func(t reflect.Type, index []int, prefix string)
+	walk = func(t reflect.Type, index []int, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			tpi := t.Field(i)
+			path := append(append([]int{}, index...), i)
+			// time.Time and the registered types are leaves, stored in one column
+			if nested := tpi.Type; (nested.Kind() == reflect.Struct || (nested.Kind() == reflect.Ptr && nested.Elem().Kind() == reflect.Struct)) && !isLeafStruct(nested) {
+				nestedPrefix, flatten, err := FlattenField(StructField{Name: tpi.Name, Type: nested.String(), Tag: tpi.Tag}, tpi.Anonymous)
+				if err != nil {
+					panic(err)
+				}
+				if flatten {
+					if nested.Kind() == reflect.Ptr {
+						nested = nested.Elem()
+					}
+					walk(nested, path, prefix+nestedPrefix)
+				}
+				continue
+			}
+			fn(prefix+tpi.Name, path, tpi)
+		}
+	}
+	walk(structType, nil, "")
+}
+
+// isLeafStruct reports whether the struct, or pointer to struct, t is stored in one column
+func isLeafStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == timeType || lookupFieldType(t) != nil
+}
+
+// tagDim returns the dim=N option of a vector field, empty when it is not set
+func tagDim(name string, tagMilvus string) (dim string, err error) {
+	if val, ok := tagOption(tagMilvus, entity.TypeParamDim); ok {
+		dim = strings.TrimRightFunc(val, func(r rune) bool { return !unicode.IsNumber(r) })
+		if _, err := strconv.Atoi(dim); err != nil {
+			return "", fmt.Errorf("%s %s is not set", name, dim)
+		}
+	}
+	return dim, nil
+}
@@ -0,0 +1,80 @@
+package qmilvus
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+)
+
+type Ownership struct {
+	Org  int64  `milvus:"in,out"`
+	Team string `milvus:"in,out,max_length=32"`
+}
+
+type Audit struct {
+	CreatedBy string `milvus:"in,out,max_length=32"`
+}
+
+type NestedDoc struct {
+	*Audit
+	Owner  Ownership  `milvus:"prefix=owner_"`
+	Backup *Ownership `milvus:"prefix=backup_"`
+	Id     int64      `milvus:"in,out,PK"`
+	Vector []float32  `milvus:"in,dim=4"`
+}
+
+func TestNestedFlattening(t *testing.T) {
+	c := NewCollection[*NestedDoc](milvusAdress)
+	var names []string
+	for _, f := range c.schemaIn.Fields {
+		names = append(names, f.Name)
+	}
+	want := []string{"CreatedBy", "owner_Org", "owner_Team", "backup_Org", "backup_Team", "Id", "Vector"}
+	if !reflect.DeepEqual(names, want) || !reflect.DeepEqual(c.outputFields, want[:6]) {
+		t.Fatalf("unexpected fields %v, outputs %v", names, c.outputFields)
+	}
+
+	in := []*NestedDoc{
+		{Audit: &Audit{CreatedBy: "ann"}, Owner: Ownership{Org: 1, Team: "a"}, Backup: &Ownership{Org: 2}, Id: 1, Vector: []float32{1, 0, 0, 0}},
+		// nil nested pointers read as zero values
+		{Owner: Ownership{Org: 3}, Id: 2, Vector: []float32{0, 1, 0, 0}},
+	}
+	columns, err := c.BuildColumns(in...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := c.ParseSearchResult(&client.SearchResult{ResultCount: len(in), Fields: columns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// nested pointers are allocated when hydrating
+	in[1].Audit, in[1].Backup = &Audit{}, &Ownership{}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("models differ after a round trip: %+v", out[1])
+	}
+}
+
+func TestFlattenField(t *testing.T) {
+	if _, _, err := FlattenField(StructField{Name: "Owner", Tag: `milvus:"in,out"`}, false); err == nil {
+		t.Errorf("a tagged nested struct without prefix should fail")
+	}
+	if _, flatten, _ := FlattenField(StructField{Name: "Owner"}, false); flatten {
+		t.Errorf("an untagged nested struct should be ignored")
+	}
+	if prefix, flatten, _ := FlattenField(StructField{Name: "Owner", Tag: `milvus:"prefix=Owner_"`}, false); !flatten || prefix != "Owner_" {
+		t.Errorf("unexpected prefix %q", prefix)
+	}
+}
+
+func TestSchemaFieldPointer(t *testing.T) {
+	for _, f := range []StructField{
+		{Name: "Views", Type: "*int64", Tag: `milvus:"in,out"`},
+		{Name: "Lang", Type: "string", Tag: `milvus:"in,out,nullable"`},
+	} {
+		if _, err := SchemaField(f); err == nil || !strings.Contains(err.Error(), "need nullable support") {
+			t.Errorf("%s %s `%s` should be unsupported, got %v", f.Name, f.Type, f.Tag, err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package qmilvus
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/doptime/qmilvus"
+
+// operation describes one collection operation, for retries, spans and metrics
+type operation struct {
+	name       string // upsert, insert, delete, search, query, create, drop, flush, compact, ...
+	idempotent bool
+	rows       int // rows written or deleted
+	nq, topK   int // search only
+	returned   int // rows returned, set by search and query once done
+}
+
+// telemetry holds the otel instruments of a collection
+type telemetry struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	rows     metric.Int64Counter
+}
+
+func newTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) *telemetry {
+	meter := mp.Meter(instrumentationName)
+	t := &telemetry{tracer: tp.Tracer(instrumentationName)}
+	// instrument errors only happen on invalid names, the noop instruments are used then
+	t.duration, _ = meter.Float64Histogram("qmilvus.operation.duration",
+		metric.WithDescription("Duration of collection operations, retries included"), metric.WithUnit("s"))
+	t.rows, _ = meter.Int64Counter("qmilvus.operation.rows",
+		metric.WithDescription("Rows written, deleted or returned by collection operations"), metric.WithUnit("{row}"))
+	return t
+}
+
+// WithTracerProvider sets the provider of the spans of every operation, the global otel provider by default
+func (collection *Collection[v]) WithTracerProvider(tp trace.TracerProvider) (ret *Collection[v]) {
+	collection.telemetry = newTelemetry(tp, collection.meterProvider)
+	collection.tracerProvider = tp
+	return collection
+}
+
+// WithMeterProvider sets the provider of the latency and row metrics, the global otel provider by default
+func (collection *Collection[v]) WithMeterProvider(mp metric.MeterProvider) (ret *Collection[v]) {
+	collection.telemetry = newTelemetry(collection.tracerProvider, mp)
+	collection.meterProvider = mp
+	return collection
+}
+
+// WithLogger sets the logger of the collection, slog.Default() by default
+func (collection *Collection[v]) WithLogger(logger *slog.Logger) (ret *Collection[v]) {
+	collection.logger = logger
+	return collection
+}
+
+func (c *Collection[v]) initTelemetry() {
+	c.tracerProvider, c.meterProvider = otel.GetTracerProvider(), otel.GetMeterProvider()
+	c.telemetry = newTelemetry(c.tracerProvider, c.meterProvider)
+	c.logger = slog.Default()
+}
+
+// do runs fn as the operation op: inside a span, under the retry policy and the circuit breaker,
+// recording its latency and row count
+func (c *Collection[v]) do(ctx context.Context, op *operation, fn func(ctx context.Context) error) (err error) {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "milvus"),
+		attribute.String("db.collection.name", c.collectionName),
+		attribute.String("db.operation.name", op.name),
+		attribute.String("milvus.partition", c.partitionName),
+	}
+	spanAttrs := attrs
+	if op.rows > 0 {
+		spanAttrs = append(spanAttrs, attribute.Int("milvus.rows", op.rows))
+	}
+	if op.nq > 0 {
+		spanAttrs = append(spanAttrs, attribute.Int("milvus.nq", op.nq), attribute.Int("milvus.top_k", op.topK))
+	}
+	ctx, span := c.telemetry.tracer.Start(ctx, "milvus."+op.name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(spanAttrs...))
+	defer span.End()
+
+	start := time.Now()
+	err = c.retry(ctx, op, fn)
+
+	status := "ok"
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else if op.returned > 0 {
+		span.SetAttributes(attribute.Int("milvus.returned", op.returned))
+	}
+	c.telemetry.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(append(attrs, attribute.String("status", status))...))
+	if err == nil && op.rows+op.returned > 0 {
+		c.telemetry.rows.Add(ctx, int64(op.rows+op.returned), metric.WithAttributes(attrs...))
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+package qmilvus
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ColumnValue are the go types of the values of the milvus scalar columns
+type ColumnValue interface {
+	bool | int8 | int16 | int32 | int64 | float32 | float64 | string
+}
+
+// fieldType is a go type registered with RegisterFieldType
+type fieldType struct {
+	column string      // go type of the column values, as SchemaField reads it, e.g. int64
+	get    interface{} // func(reflect.Value) (M, error)
+	set    interface{} // func(reflect.Value, M) error
+}
+
+// fieldTypes holds the registered field types, reflect.Type of T -> *fieldType
+var fieldTypes sync.Map
+
+// RegisterFieldType maps the model fields of type T to a milvus column of values of type M,
+// e.g. a decimal stored as a string or a version stored as an int64. register before NewCollection.
+// qmilvus-gen does not know registered types, models using them are read and written by reflection
+func RegisterFieldType[T any, M ColumnValue](marshal func(T) (M, error), unmarshal func(M) (T, error)) {
+	var column M
+	fieldTypes.Store(reflect.TypeOf((*T)(nil)).Elem(), &fieldType{
+		column: reflect.TypeOf(column).String(),
+		get: func(f reflect.Value) (M, error) {
+			// through a pointer, boxing the value would allocate
+			if f.CanAddr() {
+				return marshal(*f.Addr().Interface().(*T))
+			}
+			return marshal(f.Interface().(T))
+		},
+		set: func(f reflect.Value, x M) error {
+			value, err := unmarshal(x)
+			if err == nil {
+				*f.Addr().Interface().(*T) = value
+			}
+			return err
+		},
+	})
+}
+
+// lookupFieldType returns the registered field type of t, nil if t is not registered
+func lookupFieldType(t reflect.Type) *fieldType {
+	if ft, ok := fieldTypes.Load(t); ok {
+		return ft.(*fieldType)
+	}
+	return nil
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	float32sType = reflect.TypeOf([]float32(nil))
+	float64sType = reflect.TypeOf([]float64(nil))
+	uint16sType  = reflect.TypeOf([]uint16(nil))
+)
+
+// schemaType returns the type of a model field as SchemaField maps it: registered types by the type of their column values,
+// named types by their underlying type, e.g. int64 for time.Duration, and time.Time as it is
+func schemaType(t reflect.Type) string {
+	if ft := lookupFieldType(t); ft != nil {
+		return ft.column
+	}
+	switch k := t.Kind(); {
+	case t == timeType:
+		return "time.Time"
+	case k == reflect.Ptr:
+		return "*" + schemaType(t.Elem())
+	case k == reflect.Slice:
+		return "[]" + schemaType(t.Elem())
+	case k >= reflect.Bool && k <= reflect.Float64 || k == reflect.String:
+		return k.String()
+	}
+	return t.String()
+}
+
+// timeUnits are the units of the unit= option of time.Time fields, stored as int64 epochs
+var timeUnits = map[string]time.Duration{"s": time.Second, "ms": time.Millisecond, "us": time.Microsecond, "ns": time.Nanosecond}
+
+// TimeToEpoch returns t as the count of units since the unix epoch, 0 for the zero time.
+// used by the code qmilvus-gen generates for time.Time fields
+func TimeToEpoch(t time.Time, unit time.Duration) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	switch unit {
+	case time.Second:
+		return t.Unix()
+	case time.Microsecond:
+		return t.UnixMicro()
+	case time.Nanosecond:
+		return t.UnixNano()
+	}
+	return t.UnixMilli()
+}
+
+// EpochToTime returns the UTC time of epoch units since the unix epoch, the zero time for 0
+func EpochToTime(epoch int64, unit time.Duration) time.Time {
+	if epoch == 0 {
+		return time.Time{}
+	}
+	switch unit {
+	case time.Second:
+		return time.Unix(epoch, 0).UTC()
+	case time.Microsecond:
+		return time.UnixMicro(epoch).UTC()
+	case time.Nanosecond:
+		return time.Unix(0, epoch).UTC()
+	}
+	return time.UnixMilli(epoch).UTC()
+}
+
+// Float32s converts a []float64 vector to the []float32 milvus stores
+func Float32s(x []float64) []float32 {
+	if x == nil {
+		return nil
+	}
+	y := make([]float32, len(x))
+	for i := range x {
+		y[i] = float32(x[i])
+	}
+	return y
+}
+
+// Float64s converts a []float32 vector read from milvus to []float64
+func Float64s(x []float32) []float64 {
+	if x == nil {
+		return nil
+	}
+	y := make([]float64, len(x))
+	for i := range x {
+		y[i] = float64(x[i])
+	}
+	return y
+}
+
+// overflows returns the error of an unsigned value too large for its int column
+func overflows(fp *fieldPlan, x uint64) error {
+	return fmt.Errorf("field %s: %d overflows %s: %w", fp.name, x, fp.field.DataType.Name(), ErrInvalidArgument)
+}
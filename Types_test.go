@@ -0,0 +1,106 @@
+package qmilvus
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+type Priority int
+
+type Version struct{ Major, Minor int }
+
+func init() {
+	RegisterFieldType(func(v Version) (string, error) { return fmt.Sprintf("%d.%d", v.Major, v.Minor), nil },
+		func(s string) (v Version, err error) {
+			_, err = fmt.Sscanf(s, "%d.%d", &v.Major, &v.Minor)
+			return v, err
+		})
+}
+
+type TypedDoc struct {
+	Id       uint64        `milvus:"in,out,PK"`
+	Count    int           `milvus:"in,out"`
+	Flags    uint8         `milvus:"in,out"`
+	Priority Priority      `milvus:"in,out"`
+	Version  Version       `milvus:"in,out,max_length=16"`
+	Created  time.Time     `milvus:"in,out"`
+	Expires  time.Time     `milvus:"in,out,unit=us"`
+	Timeout  time.Duration `milvus:"in,out"`
+	Vector   []float64     `milvus:"in,dim=2"`
+}
+
+func TestFieldTypes(t *testing.T) {
+	c := NewCollection[*TypedDoc](milvusAdress)
+	types := map[string]entity.FieldType{}
+	for _, f := range c.schemaIn.Fields {
+		types[f.Name] = f.DataType
+	}
+	want := map[string]entity.FieldType{"Id": entity.FieldTypeInt64, "Count": entity.FieldTypeInt64, "Flags": entity.FieldTypeInt16, "Priority": entity.FieldTypeInt64,
+		"Version": entity.FieldTypeVarChar, "Created": entity.FieldTypeInt64, "Expires": entity.FieldTypeInt64, "Timeout": entity.FieldTypeInt64, "Vector": entity.FieldTypeFloatVector}
+	if !reflect.DeepEqual(types, want) || c.pkFieldName != "Id" {
+		t.Fatalf("unexpected schema %v, pk %s", types, c.pkFieldName)
+	}
+
+	expires := time.UnixMicro(1700000000123456).UTC()
+	in := []*TypedDoc{
+		{Id: 1, Count: -3, Flags: 255, Priority: 2, Version: Version{1, 2}, Created: time.UnixMilli(1700000000123).UTC(), Expires: expires, Timeout: time.Second, Vector: []float64{0.5, 1}},
+		// the zero time is stored as 0
+		{Id: 2, Vector: []float64{1, 0}},
+	}
+	columns, err := c.BuildColumns(in...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := c.ParseSearchResult(&client.SearchResult{ResultCount: len(in), Fields: columns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("models differ after a round trip: %+v", out[0])
+	}
+
+	// values out of the range of the field are rejected on reads too
+	for _, bad := range []entity.Column{entity.NewColumnInt16("Flags", []int16{300, 0}), entity.NewColumnInt64("Id", []int64{-1, 2})} {
+		fields := make([]entity.Column, 0, len(columns))
+		for _, column := range columns {
+			if column.Name() == bad.Name() {
+				column = bad
+			}
+			fields = append(fields, column)
+		}
+		if _, err = c.ParseSearchResult(&client.SearchResult{ResultCount: len(in), Fields: fields}); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("expected an overflow reading %s, got %v", bad.Name(), err)
+		}
+	}
+
+	in[1].Id = math.MaxUint64
+	if _, err = c.BuildColumns(in...); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected an overflow of the uint64 primary key, got %v", err)
+	}
+	if err = c.Validate(in...); err == nil {
+		t.Errorf("Validate should report the overflow")
+	}
+}
+
+func TestSchemaFieldTime(t *testing.T) {
+	spec, err := SchemaField(StructField{Name: "At", Type: "time.Time", Tag: `milvus:"in,out,unit=ns"`})
+	if err != nil || spec.Unit != time.Nanosecond || spec.Field.DataType != entity.FieldTypeInt64 {
+		t.Errorf("unexpected spec %+v, %v", spec, err)
+	}
+	if _, err = SchemaField(StructField{Name: "At", Type: "time.Time", Tag: `milvus:"in,out,unit=days"`}); err == nil {
+		t.Errorf("unit=days should fail")
+	}
+	for _, unit := range []time.Duration{time.Second, time.Millisecond, time.Microsecond, time.Nanosecond} {
+		at := time.Unix(1700000000, 0).UTC()
+		if got := EpochToTime(TimeToEpoch(at, unit), unit); !got.Equal(at) {
+			t.Errorf("unit %v: %v read back as %v", unit, at, got)
+		}
+	}
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/doptime/qmilvus"
+	"github.com/doptime/qmilvus/internal/source"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+	"golang.org/x/tools/go/packages"
+)
+
+// column is the generated code of one schema field
+type column struct {
+	Field   string // milvus field name
+	Path    string // selector of the struct field, e.g. Owner.Id for the fields of nested structs
+	Var     string // local variable of its data
+	GoType  string // element type of the column data
+	New     string // entity constructor, e.g. NewColumnInt64
+	Column  string // entity column type, e.g. ColumnInt64
+	AltCol  string // second column type accepted when reading, ColumnString for VarChar
+	Dim     int    // vectors only
+	Len     int    // length of the vector fields, Dim/8 bytes for binary vectors, 2*Dim for half vectors of bytes
+	Half    string // Float16 or BFloat16 for half vectors
+	In      bool   // written to milvus
+	Literal string // the entity.Field literal
+
+	FieldType  string   // go type of the struct field
+	underlying string   // FieldType as SchemaField reads it, e.g. int64 for time.Duration
+	Unit       string   // time.Time fields, the unit of their epochs, e.g. time.Millisecond
+	Overflow   bool     // uint and uint64 fields, checked against the int64 range
+	imports    []string // packages of FieldType
+}
+
+// Write returns the expression converting x, of the field type, to the column value type
+func (c column) Write(x string) string {
+	switch {
+	case c.Unit != "":
+		return "qmilvus.TimeToEpoch(" + x + ", " + c.Unit + ")"
+	case c.Half != "":
+		return c.writeHalf(x)
+	case c.underlying == "[]float64":
+		return "qmilvus.Float32s(" + x + ")"
+	case c.FieldType == c.GoType:
+		return x
+	}
+	return c.GoType + "(" + x + ")"
+}
+
+// Read returns the expression converting x, of the column value type, to the field type
+func (c column) Read(x string) string {
+	switch {
+	case c.Unit != "":
+		return "qmilvus.EpochToTime(" + x + ", " + c.Unit + ")"
+	case c.Half != "":
+		return c.readHalf(x)
+	case c.underlying == "[]float64":
+		return "qmilvus.Float64s(" + x + ")"
+	case c.FieldType == c.GoType:
+		return x
+	}
+	return c.FieldType + "(" + x + ")"
+}
+
+// ReadCheck returns the condition of the values x of the column out of the range of the unsigned field type, empty when every value fits
+func (c column) ReadCheck(x string) string {
+	switch c.underlying {
+	case "uint8":
+		return x + " < 0 || " + x + " > math.MaxUint8"
+	case "uint16":
+		return x + " < 0 || " + x + " > math.MaxUint16"
+	case "uint32":
+		return x + " < 0 || " + x + " > math.MaxUint32"
+	case "uint", "uint64":
+		return x + " < 0"
+	}
+	return ""
+}
+
+// writeHalf returns the expression encoding the half vector x of the field type to bytes
+func (c column) writeHalf(x string) string {
+	switch c.underlying {
+	case "[]float32":
+		return "qmilvus.Float32To" + c.Half + "(" + x + ")"
+	case "[]float64":
+		return "qmilvus.Float32To" + c.Half + "(qmilvus.Float32s(" + x + "))"
+	case "[]uint16":
+		return "qmilvus.Uint16sToBytes(" + x + ")"
+	}
+	return x
+}
+
+// readHalf returns the expression decoding the half vector bytes x to the field type
+func (c column) readHalf(x string) string {
+	switch c.underlying {
+	case "[]float32":
+		return "qmilvus." + c.Half + "ToFloat32(" + x + ")"
+	case "[]float64":
+		return "qmilvus.Float64s(qmilvus." + c.Half + "ToFloat32(" + x + "))"
+	case "[]uint16":
+		return "qmilvus.BytesToUint16s(" + x + ")"
+	}
+	return x
+}
+
+type model struct {
+	Package string
+	Imports []string // packages of the field types, time, ...
+	Type    string
+	Prefix  string // prefix of the generated funcs
+	Columns []column
+	In      []column
+}
+
+// generate returns the source of the codec of the struct typeName of pkg
+func generate(pkg *packages.Package, typeName string) ([]byte, error) {
+	st, err := source.LookupStruct(pkg, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	m := model{Package: pkg.Name, Type: typeName, Prefix: lowerFirst(typeName) + "Milvus"}
+	qualifier := types.RelativeTo(pkg.Types)
+	imports := map[string]bool{}
+	err = source.WalkStruct(st, qualifier, func(sf source.Field) error {
+		f := sf.Var
+		spec, err := qmilvus.SchemaField(qmilvus.StructField{Name: sf.Name, Type: source.SchemaType(f.Type(), qualifier), Tag: sf.Tag})
+		if err != nil || spec == nil {
+			return err
+		}
+		if sf.Indirect {
+			return fmt.Errorf("nested struct pointers are not supported by qmilvus-gen, nest the struct by value")
+		}
+		if !f.Exported() {
+			return fmt.Errorf("tagged fields should be exported")
+		}
+		col, err := columnOf(spec)
+		if err != nil {
+			return err
+		}
+		col.Path = sf.Path
+		col.underlying = source.SchemaType(f.Type(), qualifier)
+		col.FieldType = types.TypeString(f.Type(), func(p *types.Package) string {
+			if p == pkg.Types {
+				return ""
+			}
+			col.imports = append(col.imports, p.Path())
+			return p.Name()
+		})
+		col.Overflow = col.underlying == "uint" || col.underlying == "uint64"
+		if col.Half != "" && (col.underlying == "[]byte" || col.underlying == "[]uint8") {
+			col.Len = 2 * col.Dim
+		}
+		if col.Unit != "" {
+			imports["time"] = true
+		}
+		if strings.Contains(col.ReadCheck("x"), "math.") {
+			imports["math"] = true
+		}
+		// the field type is written by the conversions only
+		if strings.Contains(col.Read("x"), col.FieldType) {
+			for _, path := range col.imports {
+				imports[path] = true
+			}
+		}
+		m.Columns = append(m.Columns, col)
+		if col.In {
+			m.In = append(m.In, col)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s.%w", typeName, err)
+	}
+	for path := range imports {
+		m.Imports = append(m.Imports, path)
+	}
+	sort.Strings(m.Imports)
+
+	var buf bytes.Buffer
+	if err := codecTemplate.Execute(&buf, m); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+// columnOf maps a schema field to its column types
+func columnOf(spec *qmilvus.FieldSpec) (col column, err error) {
+	field := spec.Field
+	col = column{Field: field.Name, Var: identifier(lowerFirst(field.Name)) + "Data", In: spec.In}
+	switch field.DataType {
+	case entity.FieldTypeBool:
+		col.GoType, col.New, col.Column = "bool", "NewColumnBool", "ColumnBool"
+	case entity.FieldTypeInt8:
+		col.GoType, col.New, col.Column = "int8", "NewColumnInt8", "ColumnInt8"
+	case entity.FieldTypeInt16:
+		col.GoType, col.New, col.Column = "int16", "NewColumnInt16", "ColumnInt16"
+	case entity.FieldTypeInt32:
+		col.GoType, col.New, col.Column = "int32", "NewColumnInt32", "ColumnInt32"
+	case entity.FieldTypeInt64:
+		col.GoType, col.New, col.Column = "int64", "NewColumnInt64", "ColumnInt64"
+		if spec.Unit != 0 {
+			col.Unit = timeUnits[spec.Unit]
+		}
+	case entity.FieldTypeFloat:
+		col.GoType, col.New, col.Column = "float32", "NewColumnFloat", "ColumnFloat"
+	case entity.FieldTypeDouble:
+		col.GoType, col.New, col.Column = "float64", "NewColumnDouble", "ColumnDouble"
+	case entity.FieldTypeVarChar:
+		col.GoType, col.New, col.Column, col.AltCol = "string", "NewColumnVarChar", "ColumnVarChar", "ColumnString"
+	case entity.FieldTypeFloatVector:
+		col.GoType, col.New, col.Column = "[]float32", "NewColumnFloatVector", "ColumnFloatVector"
+		if col.Dim, err = strconv.Atoi(field.TypeParams[entity.TypeParamDim]); err != nil {
+			return col, fmt.Errorf("vector field needs dim=N")
+		}
+	case entity.FieldTypeBinaryVector:
+		col.GoType, col.New, col.Column = "[]byte", "NewColumnBinaryVector", "ColumnBinaryVector"
+		if col.Dim, err = strconv.Atoi(field.TypeParams[entity.TypeParamDim]); err != nil {
+			return col, fmt.Errorf("vector field needs dim=N")
+		}
+	case entity.FieldTypeFloat16Vector, entity.FieldTypeBFloat16Vector:
+		col.Half = strings.TrimSuffix(field.DataType.Name(), "Vector")
+		col.GoType, col.New, col.Column = "[]byte", "NewColumn"+field.DataType.Name(), "Column"+field.DataType.Name()
+		if col.Dim, err = strconv.Atoi(field.TypeParams[entity.TypeParamDim]); err != nil {
+			return col, fmt.Errorf("vector field needs dim=N")
+		}
+	default:
+		return col, fmt.Errorf("type %s not supported by qmilvus-gen", field.DataType.Name())
+	}
+	if col.Len = col.Dim; field.DataType == entity.FieldTypeBinaryVector {
+		// dim counts bits
+		col.Len = col.Dim / 8
+	}
+	col.Literal = fieldLiteral(field)
+	return col, nil
+}
+
+var timeUnits = map[time.Duration]string{time.Second: "time.Second", time.Millisecond: "time.Millisecond", time.Microsecond: "time.Microsecond", time.Nanosecond: "time.Nanosecond"}
+
+// fieldLiteral returns the go literal of field
+func fieldLiteral(field *entity.Field) string {
+	keys := make([]string, 0, len(field.TypeParams))
+	for k := range field.TypeParams {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	params := ""
+	for _, k := range keys {
+		params += fmt.Sprintf("%q: %q, ", k, field.TypeParams[k])
+	}
+	lit := fmt.Sprintf("{Name: %q, DataType: entity.FieldType%s", field.Name, field.DataType.Name())
+	if field.PrimaryKey {
+		lit += ", PrimaryKey: true"
+	}
+	if field.IsPartitionKey {
+		lit += ", IsPartitionKey: true"
+	}
+	return lit + ", TypeParams: map[string]string{" + params + "}}"
+}
+
+// identifier returns s in camel case, without the characters invalid in a go identifier
+func identifier(s string) string {
+	words := strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for i := 1; i < len(words); i++ {
+		words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+	}
+	return strings.Join(words, "")
+}
+
+// lowerFirst lowers the leading upper case letters of s: Doc -> doc, TTL -> ttl, IDField -> idField
+func lowerFirst(s string) string {
+	r := []rune(s)
+	for i := range r {
+		if !unicode.IsUpper(r[i]) || i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
+}
+
+var codecTemplate = template.Must(template.New("codec").Parse(`// Code generated by qmilvus-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"fmt"
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+
+	"github.com/doptime/qmilvus"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+func init() {
+	qmilvus.RegisterCodec(&qmilvus.Codec[*{{.Type}}]{Fields: {{.Prefix}}Fields(), Columns: {{.Prefix}}ColumnsOfPtrs, Models: {{.Prefix}}ModelsOfPtrs})
+	qmilvus.RegisterCodec(&qmilvus.Codec[{{.Type}}]{Fields: {{.Prefix}}Fields(), Columns: {{.Prefix}}ColumnsOfValues, Models: {{.Prefix}}ModelsOfValues})
+}
+
+func {{.Prefix}}Fields() []*entity.Field {
+	return []*entity.Field{
+{{- range .In}}
+		{{.Literal}},
+{{- end}}
+	}
+}
+
+func {{.Prefix}}Columns(n int, model func(i int) *{{.Type}}) ([]entity.Column, error) {
+{{- range .In}}
+	{{.Var}} := make([]{{.GoType}}, n)
+{{- end}}
+	for i := 0; i < n; i++ {
+		m := model(i)
+		if m == nil {
+			return nil, fmt.Errorf("row %d: model is nil: %w", i, qmilvus.ErrInvalidArgument)
+		}
+{{- range .In}}
+{{- if .Dim}}
+		if len(m.{{.Path}}) != {{.Len}} {
+			return nil, &qmilvus.ErrDimMismatch{Field: "{{.Field}}", Want: {{.Len}}, Got: len(m.{{.Path}}), Row: i}
+		}
+{{- end}}
+		{{.Var}}[i] = {{.Write (print "m." .Path)}}
+{{- if .Overflow}}
+		if {{.Var}}[i] < 0 {
+			return nil, fmt.Errorf("row %d field {{.Field}}: value overflows Int64: %w", i, qmilvus.ErrInvalidArgument)
+		}
+{{- end}}
+{{- end}}
+	}
+	return []entity.Column{
+{{- range .In}}
+		entity.{{.New}}("{{.Field}}", {{if .Dim}}{{.Dim}}, {{end}}{{.Var}}),
+{{- end}}
+	}, nil
+}
+
+func {{.Prefix}}Fill(models []{{.Type}}, columns []entity.Column) error {
+	for _, column := range columns {
+		if column.Len() != len(models) {
+			return fmt.Errorf("column '%s' length (%d) does not match model count (%d)", column.Name(), column.Len(), len(models))
+		}
+		switch column.Name() {
+{{- range .Columns}}
+		case "{{.Field}}":
+			switch col := column.(type) {
+			case *entity.{{.Column}}:
+{{- template "fill" .}}
+{{- if .AltCol}}
+			case *entity.{{.AltCol}}:
+{{- template "fill" .}}
+{{- end}}
+			default:
+				return fmt.Errorf("type mismatch for field '{{.Field}}': expected {{.GoType}}, got %T from Milvus", column)
+			}
+{{- end}}
+		}
+	}
+	return nil
+}
+
+func {{.Prefix}}ColumnsOfPtrs(models []*{{.Type}}) ([]entity.Column, error) {
+	return {{.Prefix}}Columns(len(models), func(i int) *{{.Type}} { return models[i] })
+}
+
+func {{.Prefix}}ColumnsOfValues(models []{{.Type}}) ([]entity.Column, error) {
+	return {{.Prefix}}Columns(len(models), func(i int) *{{.Type}} { return &models[i] })
+}
+
+func {{.Prefix}}ModelsOfPtrs(count int, columns []entity.Column) ([]*{{.Type}}, error) {
+	values, err := {{.Prefix}}ModelsOfValues(count, columns)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*{{.Type}}, count)
+	for i := range values {
+		models[i] = &values[i]
+	}
+	return models, nil
+}
+
+func {{.Prefix}}ModelsOfValues(count int, columns []entity.Column) ([]{{.Type}}, error) {
+	models := make([]{{.Type}}, count)
+	if err := {{.Prefix}}Fill(models, columns); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+{{- define "fill"}}
+				for i, x := range col.Data() {
+{{- if .ReadCheck "x"}}
+					if {{.ReadCheck "x"}} {
+						return fmt.Errorf("row %d field {{.Field}}: %d overflows {{.FieldType}}: %w", i, x, qmilvus.ErrInvalidArgument)
+					}
+{{- end}}
+					models[i].{{.Path}} = {{.Read "x"}}
+				}
+{{- end}}
+`))
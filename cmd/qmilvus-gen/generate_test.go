@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/doptime/qmilvus/internal/source"
+)
+
+// the codec checked in the example package is up to date with the generator
+func TestGenerateExample(t *testing.T) {
+	pkg, err := source.Load("../../example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := generate(pkg, "Doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("../../example/doc_qmilvus.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("example/doc_qmilvus.go is stale, run go generate ./example")
+	}
+
+	if _, err = generate(pkg, "Missing"); err == nil {
+		t.Errorf("expected an error for a missing type")
+	}
+}
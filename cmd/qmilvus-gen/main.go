@@ -0,0 +1,63 @@
+// qmilvus-gen generates reflection-free codecs for qmilvus models: the schema fields,
+// columns from a slice of models and models from the columns milvus returns.
+// Collection[v] picks the generated codec up in NewCollection.
+//
+//	//go:generate go run github.com/doptime/qmilvus/cmd/qmilvus-gen -type FooEntity
+//
+// it writes fooentity_qmilvus.go next to the package of FooEntity
+// run `go get github.com/doptime/qmilvus/cmd/qmilvus-gen` once, so that go.sum lists the dependencies of the generator
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/doptime/qmilvus/internal/source"
+)
+
+func main() {
+	var (
+		typeNames = flag.String("type", "", "comma separated names of the model structs, required")
+		output    = flag.String("output", "", "output file name, default <type>_qmilvus.go, only with a single type")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: qmilvus-gen -type T[,T...] [-output file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	types := strings.Split(*typeNames, ",")
+	if *typeNames == "" || (*output != "" && len(types) > 1) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	pkg, err := source.Load(dir)
+	if err != nil {
+		fail(err)
+	}
+	for _, typeName := range types {
+		src, err := generate(pkg, typeName)
+		if err != nil {
+			fail(err)
+		}
+		name := *output
+		if name == "" {
+			name = strings.ToLower(typeName) + "_qmilvus.go"
+		}
+		if err = os.WriteFile(filepath.Join(dir, name), src, 0o644); err != nil {
+			fail(err)
+		}
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "qmilvus-gen:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/doptime/qmilvus"
+	"github.com/milvus-io/milvus-proto/go-api/v2/commonpb"
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+// admin parses the flags of an admin command taking nargs arguments, any count when nargs < 0,
+// and runs fn with a client of the milvus at -addr
+func admin(fs *flag.FlagSet, args []string, nargs int, fn func(c client.Client, args []string) error) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if nargs >= 0 && fs.NArg() != nargs || nargs < 0 && fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	c, err := qmilvus.Dial(*address)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	return fn(c, fs.Args())
+}
+
+// partitionsFlag adds the -partitions flag, the comma separated partitions the command is restricted to
+func partitionsFlag(fs *flag.FlagSet) func() []string {
+	partitions := fs.String("partitions", "", "comma separated partition names, all partitions by default")
+	return func() []string { return splitList(*partitions) }
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, x := range strings.Split(s, ",") {
+		if x = strings.TrimSpace(x); x != "" {
+			list = append(list, x)
+		}
+	}
+	return list
+}
+
+func runCollections(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	return admin(fs, args, 0, func(c client.Client, _ []string) error {
+		collections, err := c.ListCollections(ctx)
+		if err != nil {
+			return err
+		}
+		sort.Slice(collections, func(i, j int) bool { return collections[i].Name < collections[j].Name })
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tID\tLOADED")
+		for _, coll := range collections {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", coll.Name, coll.ID, check(coll.Loaded))
+		}
+		return tw.Flush()
+	})
+}
+
+func runDescribe(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	return admin(fs, args, 1, func(c client.Client, args []string) error {
+		coll, err := c.DescribeCollection(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		aliases, err := qmilvus.ListAliases(ctx, c, coll.Name)
+		if err != nil {
+			return err
+		}
+		state, err := c.GetLoadState(ctx, coll.Name, nil)
+		if err != nil {
+			return err
+		}
+		var properties []string
+		for k, v := range coll.Properties {
+			properties = append(properties, k+"="+v)
+		}
+		sort.Strings(properties)
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "collection\t%s\n", coll.Name)
+		fmt.Fprintf(tw, "id\t%d\n", coll.ID)
+		fmt.Fprintf(tw, "description\t%s\n", coll.Schema.Description)
+		fmt.Fprintf(tw, "shards\t%d\n", coll.ShardNum)
+		fmt.Fprintf(tw, "consistency\t%s\n", coll.ConsistencyLevel.CommonConsistencyLevel())
+		fmt.Fprintf(tw, "load state\t%s\n", loadStates[state])
+		fmt.Fprintf(tw, "aliases\t%s\n", strings.Join(aliases, ", "))
+		fmt.Fprintf(tw, "properties\t%s\n\n", strings.Join(properties, ", "))
+
+		fmt.Fprintln(tw, "FIELD\tTYPE\tPARAMS\tKEY")
+		for _, f := range coll.Schema.Fields {
+			var params []string
+			for k, v := range f.TypeParams {
+				params = append(params, k+"="+v)
+			}
+			sort.Strings(params)
+			key := ""
+			switch {
+			case f.PrimaryKey && f.AutoID:
+				key = "primary, auto id"
+			case f.PrimaryKey:
+				key = "primary"
+			case f.IsPartitionKey:
+				key = "partition"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Name, f.DataType.Name(), strings.Join(params, ","), key)
+		}
+		return tw.Flush()
+	})
+}
+
+var loadStates = map[entity.LoadState]string{
+	entity.LoadStateNotExist: "not exist",
+	entity.LoadStateNotLoad:  "released",
+	entity.LoadStateLoading:  "loading",
+	entity.LoadStateLoaded:   "loaded",
+}
+
+func runCount(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	partitions := partitionsFlag(fs)
+	expr := fs.String("expr", "", "filter expression, all rows by default")
+	return admin(fs, args, 1, func(c client.Client, args []string) error {
+		state, err := c.GetLoadState(ctx, args[0], nil)
+		if err != nil {
+			return err
+		}
+		if state != entity.LoadStateLoaded {
+			if *expr != "" || len(partitions()) > 0 {
+				return fmt.Errorf("collection %s is not loaded, count(*) of a filter or a partition needs it loaded", args[0])
+			}
+			// the row count of the collection statistics, deleted rows included until compaction
+			stats, err := c.GetCollectionStatistics(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s (approximate, the collection is not loaded)\n", stats["row_count"])
+			return nil
+		}
+		rs, err := c.Query(ctx, args[0], partitions(), *expr, []string{"count(*)"})
+		if err != nil {
+			return err
+		}
+		count, err := rs.GetColumn("count(*)").GetAsInt64(0)
+		if err != nil {
+			return err
+		}
+		fmt.Println(count)
+		return nil
+	})
+}
+
+func runPartitions(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	return admin(fs, args, 1, func(c client.Client, args []string) error {
+		partitions, err := c.ShowPartitions(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tID\tLOADED")
+		for _, p := range partitions {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", p.Name, p.ID, check(p.Loaded))
+		}
+		return tw.Flush()
+	})
+}
+
+func runIndexes(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	return admin(fs, args, 1, func(c client.Client, args []string) error {
+		coll, err := c.DescribeCollection(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "FIELD\tINDEX\tMETRIC\tSTATE\tINDEXED ROWS")
+		for _, f := range coll.Schema.Fields {
+			indexes, err := c.DescribeIndex(ctx, coll.Name, f.Name)
+			if err != nil && !errors.Is(err, qmilvus.ErrIndexNotFound) {
+				return err
+			}
+			if len(indexes) == 0 {
+				// scalar fields are rarely indexed, report the vectors only
+				if isVector(f.DataType) {
+					fmt.Fprintf(tw, "%s\tnone\t\t\t\n", f.Name)
+				}
+				continue
+			}
+			state, err := c.GetIndexState(ctx, coll.Name, f.Name)
+			if err != nil {
+				return err
+			}
+			total, indexed, err := c.GetIndexBuildProgress(ctx, coll.Name, f.Name)
+			if err != nil {
+				return err
+			}
+			params := indexes[0].Params()
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d/%d\n", f.Name, indexes[0].IndexType(), params["metric_type"], commonpb.IndexState(state), indexed, total)
+		}
+		return tw.Flush()
+	})
+}
+
+func isVector(t entity.FieldType) bool {
+	switch t {
+	case entity.FieldTypeFloatVector, entity.FieldTypeBinaryVector, entity.FieldTypeFloat16Vector, entity.FieldTypeBFloat16Vector:
+		return true
+	}
+	return false
+}
+
+func runLoad(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	partitions := partitionsFlag(fs)
+	return admin(fs, args, 1, func(c client.Client, args []string) error {
+		// not async, returns once loaded
+		if p := partitions(); len(p) > 0 {
+			return c.LoadPartitions(ctx, args[0], p, false)
+		}
+		return c.LoadCollection(ctx, args[0], false)
+	})
+}
+
+func runRelease(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	partitions := partitionsFlag(fs)
+	return admin(fs, args, 1, func(c client.Client, args []string) error {
+		if p := partitions(); len(p) > 0 {
+			return c.ReleasePartitions(ctx, args[0], p)
+		}
+		return c.ReleaseCollection(ctx, args[0])
+	})
+}
+
+func runFlush(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	return admin(fs, args, 1, func(c client.Client, args []string) error {
+		return c.Flush(ctx, args[0], false)
+	})
+}
+
+func runCompact(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	return admin(fs, args, 1, func(c client.Client, args []string) error {
+		id, err := c.ManualCompaction(ctx, args[0], 0)
+		if err != nil {
+			return err
+		}
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			state, err := c.GetCompactionState(ctx, id)
+			if err != nil {
+				return err
+			}
+			if state == entity.CompactionStateCompleted {
+				fmt.Printf("compaction %d completed\n", id)
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("compaction %d of collection %s not completed: %w", id, args[0], ctx.Err())
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+func runAlias(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	return admin(fs, args, -1, func(c client.Client, args []string) error {
+		switch {
+		case args[0] == "list" && len(args) == 2:
+			aliases, err := qmilvus.ListAliases(ctx, c, args[1])
+			for _, alias := range aliases {
+				fmt.Println(alias)
+			}
+			return err
+		case args[0] == "create" && len(args) == 3:
+			return c.CreateAlias(ctx, args[1], args[2])
+		case args[0] == "alter" && len(args) == 3:
+			// points the existing alias to the collection
+			return c.AlterAlias(ctx, args[1], args[2])
+		case args[0] == "drop" && len(args) == 2:
+			return c.DropAlias(ctx, args[1])
+		}
+		fs.Usage()
+		return flag.ErrHelp
+	})
+}
+
+func runDrop(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	yes := fs.Bool("yes", false, "drop without confirmation")
+	return admin(fs, args, 1, func(c client.Client, args []string) error {
+		if !*yes && !confirm(os.Stdin, os.Stderr, args[0]) {
+			return fmt.Errorf("collection %s not dropped", args[0])
+		}
+		return c.DropCollection(ctx, args[0])
+	})
+}
+
+// confirm asks to type the name of the collection, dropped only when it matches
+func confirm(r io.Reader, w io.Writer, collection string) bool {
+	fmt.Fprintf(w, "dropping %s deletes all its data, type its name to confirm: ", collection)
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && line == "" {
+		return false
+	}
+	return strings.TrimSpace(line) == collection
+}
+
+func runQuery(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	partitions := partitionsFlag(fs)
+	var (
+		expr   = fs.String("expr", "", "filter expression, e.g. 'Id in [1, 2]'")
+		fields = fs.String("fields", "*", "comma separated output fields")
+		limit  = fs.Int("limit", 100, "max number of rows, 0 for no limit but -expr is then required")
+	)
+	return admin(fs, args, 1, func(c client.Client, args []string) error {
+		var opts []client.SearchQueryOptionFunc
+		if *limit > 0 {
+			opts = append(opts, client.WithLimit(int64(*limit)))
+		}
+		rs, err := c.Query(ctx, args[0], partitions(), *expr, splitList(*fields), opts...)
+		if err != nil {
+			return err
+		}
+		rows, err := jsonRows(rs)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		for _, row := range rows {
+			if err = enc.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// jsonRows returns the rows of the result set as json objects: half vectors decoded to floats,
+// binary vectors base64 encoded, json fields kept as they are
+func jsonRows(rs client.ResultSet) ([]map[string]interface{}, error) {
+	rows := make([]map[string]interface{}, rs.Len())
+	for i := range rows {
+		rows[i] = make(map[string]interface{}, len(rs))
+	}
+	for _, column := range rs {
+		for i := range rows {
+			value, err := column.Get(i)
+			if err != nil {
+				return nil, fmt.Errorf("column %s row %d: %w", column.Name(), i, err)
+			}
+			switch column.Type() {
+			case entity.FieldTypeFloat16Vector:
+				value = qmilvus.Float16ToFloat32(value.([]byte))
+			case entity.FieldTypeBFloat16Vector:
+				value = qmilvus.BFloat16ToFloat32(value.([]byte))
+			case entity.FieldTypeJSON:
+				if b, ok := value.([]byte); ok && json.Valid(b) {
+					value = json.RawMessage(b)
+				}
+			}
+			rows[i][column.Name()] = value
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/doptime/qmilvus"
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+func TestJSONRows(t *testing.T) {
+	rs := client.ResultSet{
+		entity.NewColumnInt64("Id", []int64{1, 2}),
+		entity.NewColumnVarChar("Title", []string{"a", "b"}),
+		entity.NewColumnFloat16Vector("Thumb", 2, [][]byte{qmilvus.Float32ToFloat16([]float32{1, 0.5}), qmilvus.Float32ToFloat16([]float32{-2, 0})}),
+		entity.NewColumnBinaryVector("Hash", 8, [][]byte{{0xff}, {0x01}}),
+		entity.NewColumnJSONBytes("Meta", [][]byte{[]byte(`{"x":1}`), []byte(`[]`)}),
+	}
+	rows, err := jsonRows(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"Hash":"/w==","Id":1,"Meta":{"x":1},"Thumb":[1,0.5],"Title":"a"},{"Hash":"AQ==","Id":2,"Meta":[],"Thumb":[-2,0],"Title":"b"}]`
+	if string(b) != want {
+		t.Errorf("rows encoded as\n%s\nwant\n%s", b, want)
+	}
+}
+
+func TestConfirmDrop(t *testing.T) {
+	var prompt strings.Builder
+	if !confirm(strings.NewReader("Docs\n"), &prompt, "Docs") || !strings.Contains(prompt.String(), "Docs") {
+		t.Errorf("typing the name should confirm")
+	}
+	for _, input := range []string{"", "y\n", "docs\n"} {
+		if confirm(strings.NewReader(input), &prompt, "Docs") {
+			t.Errorf("%q should not confirm dropping Docs", input)
+		}
+	}
+	if got := splitList(" a, ,b,"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("splitList = %q", got)
+	}
+}
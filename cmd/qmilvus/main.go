@@ -0,0 +1,106 @@
+// qmilvus inspects qmilvus models and the milvus collections they are stored in.
+//
+//	qmilvus schema [-json] ./pkg/model FooEntity
+//
+// prints the collection schema NewCollection derives from the struct FooEntity, read from source.
+// the admin commands run against the milvus at -addr, $MILVUS_ADDRESS by default, connected as the collections are
+//
+//	qmilvus -addr milvus.lan collections
+//	qmilvus query -expr 'Id > 10' -limit 5 FooEntitys
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+var (
+	address = flag.String("addr", envOr("MILVUS_ADDRESS", "localhost:19530"), "milvus address host[:port], $MILVUS_ADDRESS by default")
+	timeout = flag.Duration("timeout", 10*time.Minute, "timeout of the command")
+)
+
+// command is a subcommand of qmilvus
+type command struct {
+	name string
+	args string
+	help string
+	run  func(ctx context.Context, fs *flag.FlagSet, args []string) error
+}
+
+var commands = []command{
+	{"schema", "[-json] dir Type", "print the collection schema of the struct Type of the package in dir", runSchema},
+	{"collections", "", "list the collections", runCollections},
+	{"describe", "collection", "print the fields, properties, aliases and load state of the collection", runDescribe},
+	{"count", "[-expr e] [-partitions p] collection", "print the row count, by count(*) once loaded", runCount},
+	{"partitions", "collection", "list the partitions of the collection", runPartitions},
+	{"indexes", "collection", "print the indexes of the collection and their build progress", runIndexes},
+	{"load", "[-partitions p] collection", "load the collection or its partitions and wait until loaded", runLoad},
+	{"release", "[-partitions p] collection", "release the collection or its partitions from memory", runRelease},
+	{"flush", "collection", "seal and persist the growing segments", runFlush},
+	{"compact", "collection", "compact the segments and wait until completed", runCompact},
+	{"alias", "list coll | create coll alias | alter coll alias | drop alias", "manage the aliases of the collections", runAlias},
+	{"drop", "[-yes] collection", "drop the collection, after typing its name unless -yes", runDrop},
+	{"query", "[-expr e] [-fields f] [-limit n] [-partitions p] collection", "print the matching rows as json lines", runQuery},
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: qmilvus [flags] command [arguments]\n\ncommands:\n")
+		for _, cmd := range commands {
+			fmt.Fprintf(os.Stderr, "  %-40s %s\n", cmd.name+" "+cmd.args, cmd.help)
+		}
+		fmt.Fprintf(os.Stderr, "\nflags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	for _, cmd := range commands {
+		if cmd.name != flag.Arg(0) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		err := cmd.run(ctx, cmd.flagSet(), flag.Args()[1:])
+		stop()
+		cancel()
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		if err != nil {
+			fail(err)
+		}
+		return
+	}
+	flag.Usage()
+	os.Exit(2)
+}
+
+// flagSet returns the flags of the subcommand, printing its usage on errors
+func (cmd command) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: qmilvus %s %s\n\n%s\n", cmd.name, cmd.args, cmd.help)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "qmilvus:", err)
+	os.Exit(1)
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
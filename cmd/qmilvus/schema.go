@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/types"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/doptime/qmilvus"
+	"github.com/doptime/qmilvus/internal/source"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+	"golang.org/x/tools/go/packages"
+)
+
+// schemaReport is the collection NewCollection derives from a model struct
+type schemaReport struct {
+	Collection   string        `json:"collection"`
+	Description  string        `json:"description"`
+	Fields       []fieldReport `json:"fields"`
+	OutputFields []string      `json:"output_fields"`
+	VectorField  string        `json:"vector_field,omitempty"` // searched by SearchVector
+	Indexes      []indexReport `json:"indexes"`
+}
+
+type fieldReport struct {
+	Name         string `json:"name"`
+	GoType       string `json:"go_type"`
+	Type         string `json:"type"`
+	Dim          int    `json:"dim,omitempty"`
+	MaxLength    int    `json:"max_length,omitempty"`
+	PrimaryKey   bool   `json:"primary_key,omitempty"`
+	PartitionKey bool   `json:"partition_key,omitempty"`
+	In           bool   `json:"in"` // a field of the collection, written by Upsert
+	Out          bool   `json:"out"`
+}
+
+// indexReport is the index Create builds on a vector field, unless set by WithFieldIndex or WithCreateIndex
+type indexReport struct {
+	Field  string            `json:"field"`
+	Type   string            `json:"type"`
+	Metric string            `json:"metric"`
+	Params map[string]string `json:"params"`
+}
+
+func runSchema(_ context.Context, fs *flag.FlagSet, args []string) error {
+	asJSON := fs.Bool("json", false, "print the schema as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	pkg, err := source.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	report, err := schemaOf(pkg, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return report.writeTable(os.Stdout)
+}
+
+// schemaOf maps the struct typeName of pkg as NewCollection does
+func schemaOf(pkg *packages.Package, typeName string) (*schemaReport, error) {
+	st, err := source.LookupStruct(pkg, typeName)
+	if err != nil {
+		return nil, err
+	}
+	report := &schemaReport{Collection: typeName + "s", Description: "collection of " + typeName + "s", OutputFields: []string{}}
+	qualifier := types.RelativeTo(pkg.Types)
+	seen, pk := map[string]bool{}, ""
+	err = source.WalkStruct(st, qualifier, func(sf source.Field) error {
+		if qmilvus.OutputField(sf.Tag) {
+			report.OutputFields = append(report.OutputFields, sf.Name)
+		}
+		spec, err := qmilvus.SchemaField(qmilvus.StructField{Name: sf.Name, Type: source.SchemaType(sf.Var.Type(), qualifier), Tag: sf.Tag})
+		if err != nil || spec == nil {
+			return err
+		}
+		if seen[sf.Name] {
+			return fmt.Errorf("field %s is declared twice, set a prefix on the nested structs", sf.Name)
+		}
+		seen[sf.Name] = true
+		field := spec.Field
+		if field.PrimaryKey {
+			if pk != "" {
+				return fmt.Errorf("primarykey should be unique, %s and %s are set as primary key", pk, sf.Name)
+			}
+			pk = sf.Name
+		}
+		fr := fieldReport{
+			Name:         sf.Name,
+			GoType:       types.TypeString(sf.Var.Type(), qualifier),
+			Type:         field.DataType.Name(),
+			PrimaryKey:   field.PrimaryKey,
+			PartitionKey: field.IsPartitionKey,
+			In:           spec.In,
+			Out:          qmilvus.OutputField(sf.Tag),
+		}
+		fr.Dim, _ = strconv.Atoi(field.TypeParams[entity.TypeParamDim])
+		fr.MaxLength, _ = strconv.Atoi(field.TypeParams[entity.TypeParamMaxLength])
+		report.Fields = append(report.Fields, fr)
+		if !spec.In || fr.Dim == 0 {
+			return nil
+		}
+		// vectors of the collection, the first float vector is searched by default
+		if report.VectorField == "" && field.DataType != entity.FieldTypeBinaryVector {
+			report.VectorField = sf.Name
+		}
+		index, err := qmilvus.DefaultIndex(field)
+		if err != nil {
+			return err
+		}
+		params := index.Params()
+		ir := indexReport{Field: sf.Name, Type: string(index.IndexType()), Metric: params["metric_type"], Params: map[string]string{}}
+		// the build params are json encoded under params
+		var build map[string]interface{}
+		if err = json.Unmarshal([]byte(params["params"]), &build); err != nil {
+			return fmt.Errorf("params of index %s: %w", index.IndexType(), err)
+		}
+		for k, v := range build {
+			ir.Params[k] = fmt.Sprint(v)
+		}
+		report.Indexes = append(report.Indexes, ir)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s.%w", typeName, err)
+	}
+	return report, nil
+}
+
+// writeTable prints the report as aligned text
+func (r *schemaReport) writeTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "collection\t%s\n", r.Collection)
+	fmt.Fprintf(tw, "output fields\t%s\n", strings.Join(r.OutputFields, ", "))
+	fmt.Fprintf(tw, "vector field\t%s\n\n", r.VectorField)
+
+	fmt.Fprintln(tw, "FIELD\tGO TYPE\tTYPE\tDIM\tMAX LENGTH\tKEY\tIN\tOUT")
+	for _, f := range r.Fields {
+		key := ""
+		switch {
+		case f.PrimaryKey:
+			key = "primary"
+		case f.PartitionKey:
+			key = "partition"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", f.Name, f.GoType, f.Type, blankZero(f.Dim), blankZero(f.MaxLength), key, check(f.In), check(f.Out))
+	}
+
+	fmt.Fprintln(tw, "\nINDEX\tTYPE\tMETRIC\tPARAMS")
+	for _, index := range r.Indexes {
+		var params []string
+		for k, v := range index.Params {
+			params = append(params, k+"="+v)
+		}
+		sort.Strings(params)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", index.Field, index.Type, index.Metric, strings.Join(params, ","))
+	}
+	return tw.Flush()
+}
+
+func blankZero(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func check(b bool) string {
+	if b {
+		return "x"
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/doptime/qmilvus/internal/source"
+)
+
+func TestSchemaOfExample(t *testing.T) {
+	pkg, err := source.Load("../../example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := schemaOf(pkg, "Doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Collection != "Docs" || report.VectorField != "Vector" {
+		t.Errorf("unexpected collection %s, vector field %s", report.Collection, report.VectorField)
+	}
+	fields := map[string]fieldReport{}
+	for _, f := range report.Fields {
+		fields[f.Name] = f
+	}
+	if f := fields["Id"]; !f.PrimaryKey || f.Type != "Int64" {
+		t.Errorf("Id should be the int64 primary key, got %+v", f)
+	}
+	if f := fields["Vector"]; f.Dim != 128 || f.Out {
+		t.Errorf("Vector should be an input vector of dim 128, got %+v", f)
+	}
+	if f := fields["owner_Team"]; f.MaxLength != 64 {
+		t.Errorf("nested fields should be prefixed and keep their max_length, got %+v", f)
+	}
+	if len(report.Indexes) != 3 || report.Indexes[2].Field != "Hash" || report.Indexes[2].Metric != "HAMMING" {
+		t.Errorf("every vector should be indexed, got %+v", report.Indexes)
+	}
+
+	var buf bytes.Buffer
+	if err = report.writeTable(&buf); err != nil || !strings.Contains(buf.String(), "owner_Team") {
+		t.Errorf("table lacks the fields: %v\n%s", err, buf.String())
+	}
+	if _, err = schemaOf(pkg, "Ownership"); err != nil {
+		t.Errorf("structs without a primary key are mapped too: %v", err)
+	}
+	if _, err = schemaOf(pkg, "DocKind"); err == nil {
+		t.Errorf("DocKind is not a struct")
+	}
+}
@@ -2,18 +2,29 @@ package qmilvus
 
 import "github.com/milvus-io/milvus-sdk-go/v2/client"
 
-// 使用 sync.Once 确保客户端只初始化一次
+// 客户端只初始化一次, 连接失败时下次调用再重连
 func (c *Collection[v]) getClient() (client.Client, error) {
-	c.clientOnce.Do(func() {
-		c.client, c.clientErr = c.NewGrpcClient(c.ctx)
-	})
-	return c.client, c.clientErr
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
+	if c.client != nil {
+		return c.client, nil
+	}
+	_client, err := c.NewGrpcClient(c.ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.client = _client
+	return c.client, nil
 }
 
 // 在适当的时候(如 Close 方法)关闭客户端
 func (c *Collection[v]) Close() error {
+	c.clientMu.Lock()
+	defer c.clientMu.Unlock()
 	if c.client != nil {
-		return c.client.Close()
+		err := c.client.Close()
+		c.client = nil
+		return err
 	}
 	return nil
 }
@@ -0,0 +1,50 @@
+// Package example shows a model with a codec generated by qmilvus-gen, and benchmarks it against reflection
+package example
+
+import "time"
+
+//go:generate go run github.com/doptime/qmilvus/cmd/qmilvus-gen -type Doc
+
+// Audit is shared by the models, its fields are flattened into the columns of the models embedding it
+type Audit struct {
+	CreatedBy string `milvus:"in,out,max_length=64"`
+	CreatedAt int64  `milvus:"in,out"`
+}
+
+// Ownership is nested in Doc, its columns are prefixed owner_
+type Ownership struct {
+	Id   int64  `milvus:"in,out"`
+	Team string `milvus:"in,out,max_length=64"`
+}
+
+// DocKind is stored as VarChar
+type DocKind string
+
+const (
+	KindArticle DocKind = "article"
+	KindNote    DocKind = "note"
+)
+
+type Doc struct {
+	Audit
+	Owner   Ownership `milvus:"prefix=owner_"`
+	Id      int64     `milvus:"in,out,PK"`
+	Title   string    `milvus:"in,out,max_length=256"`
+	Lang    string    `milvus:"in,out,max_length=8"`
+	Rank    float32   `milvus:"in,out"`
+	Weight  float64   `milvus:"in,out"`
+	Views   int32     `milvus:"in,out"`
+	Visible bool      `milvus:"in,out"`
+	Kind    DocKind   `milvus:"in,out,max_length=16"`
+	// stored as int64 epoch seconds
+	PublishedAt time.Time     `milvus:"in,out,unit=s"`
+	TTL         time.Duration `milvus:"in,out"`
+	Shards      uint16        `milvus:"in,out"`
+	Size        uint64        `milvus:"in,out"`
+	Vector      []float32     `milvus:"in,dim=128"`
+	// half the size of Vector, converted from and to float32
+	Thumb []float32 `milvus:"in,dim=8,vector=float16"`
+	// perceptual hash, searched by SearchBinary
+	Hash  []byte  `milvus:"in,out,dim=64"`
+	Score float32 ``
+}
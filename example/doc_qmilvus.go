@@ -0,0 +1,357 @@
+// Code generated by qmilvus-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/doptime/qmilvus"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+func init() {
+	qmilvus.RegisterCodec(&qmilvus.Codec[*Doc]{Fields: docMilvusFields(), Columns: docMilvusColumnsOfPtrs, Models: docMilvusModelsOfPtrs})
+	qmilvus.RegisterCodec(&qmilvus.Codec[Doc]{Fields: docMilvusFields(), Columns: docMilvusColumnsOfValues, Models: docMilvusModelsOfValues})
+}
+
+func docMilvusFields() []*entity.Field {
+	return []*entity.Field{
+		{Name: "CreatedBy", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "64"}},
+		{Name: "CreatedAt", DataType: entity.FieldTypeInt64, TypeParams: map[string]string{}},
+		{Name: "owner_Id", DataType: entity.FieldTypeInt64, TypeParams: map[string]string{}},
+		{Name: "owner_Team", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "64"}},
+		{Name: "Id", DataType: entity.FieldTypeInt64, PrimaryKey: true, TypeParams: map[string]string{}},
+		{Name: "Title", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "256"}},
+		{Name: "Lang", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "8"}},
+		{Name: "Rank", DataType: entity.FieldTypeFloat, TypeParams: map[string]string{}},
+		{Name: "Weight", DataType: entity.FieldTypeDouble, TypeParams: map[string]string{}},
+		{Name: "Views", DataType: entity.FieldTypeInt32, TypeParams: map[string]string{}},
+		{Name: "Visible", DataType: entity.FieldTypeBool, TypeParams: map[string]string{}},
+		{Name: "Kind", DataType: entity.FieldTypeVarChar, TypeParams: map[string]string{"max_length": "16"}},
+		{Name: "PublishedAt", DataType: entity.FieldTypeInt64, TypeParams: map[string]string{}},
+		{Name: "TTL", DataType: entity.FieldTypeInt64, TypeParams: map[string]string{}},
+		{Name: "Shards", DataType: entity.FieldTypeInt32, TypeParams: map[string]string{}},
+		{Name: "Size", DataType: entity.FieldTypeInt64, TypeParams: map[string]string{}},
+		{Name: "Vector", DataType: entity.FieldTypeFloatVector, TypeParams: map[string]string{"dim": "128"}},
+		{Name: "Thumb", DataType: entity.FieldTypeFloat16Vector, TypeParams: map[string]string{"dim": "8"}},
+		{Name: "Hash", DataType: entity.FieldTypeBinaryVector, TypeParams: map[string]string{"dim": "64"}},
+	}
+}
+
+func docMilvusColumns(n int, model func(i int) *Doc) ([]entity.Column, error) {
+	createdByData := make([]string, n)
+	createdAtData := make([]int64, n)
+	ownerIdData := make([]int64, n)
+	ownerTeamData := make([]string, n)
+	idData := make([]int64, n)
+	titleData := make([]string, n)
+	langData := make([]string, n)
+	rankData := make([]float32, n)
+	weightData := make([]float64, n)
+	viewsData := make([]int32, n)
+	visibleData := make([]bool, n)
+	kindData := make([]string, n)
+	publishedAtData := make([]int64, n)
+	ttlData := make([]int64, n)
+	shardsData := make([]int32, n)
+	sizeData := make([]int64, n)
+	vectorData := make([][]float32, n)
+	thumbData := make([][]byte, n)
+	hashData := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		m := model(i)
+		if m == nil {
+			return nil, fmt.Errorf("row %d: model is nil: %w", i, qmilvus.ErrInvalidArgument)
+		}
+		createdByData[i] = m.Audit.CreatedBy
+		createdAtData[i] = m.Audit.CreatedAt
+		ownerIdData[i] = m.Owner.Id
+		ownerTeamData[i] = m.Owner.Team
+		idData[i] = m.Id
+		titleData[i] = m.Title
+		langData[i] = m.Lang
+		rankData[i] = m.Rank
+		weightData[i] = m.Weight
+		viewsData[i] = m.Views
+		visibleData[i] = m.Visible
+		kindData[i] = string(m.Kind)
+		publishedAtData[i] = qmilvus.TimeToEpoch(m.PublishedAt, time.Second)
+		ttlData[i] = int64(m.TTL)
+		shardsData[i] = int32(m.Shards)
+		sizeData[i] = int64(m.Size)
+		if sizeData[i] < 0 {
+			return nil, fmt.Errorf("row %d field Size: value overflows Int64: %w", i, qmilvus.ErrInvalidArgument)
+		}
+		if len(m.Vector) != 128 {
+			return nil, &qmilvus.ErrDimMismatch{Field: "Vector", Want: 128, Got: len(m.Vector), Row: i}
+		}
+		vectorData[i] = m.Vector
+		if len(m.Thumb) != 8 {
+			return nil, &qmilvus.ErrDimMismatch{Field: "Thumb", Want: 8, Got: len(m.Thumb), Row: i}
+		}
+		thumbData[i] = qmilvus.Float32ToFloat16(m.Thumb)
+		if len(m.Hash) != 8 {
+			return nil, &qmilvus.ErrDimMismatch{Field: "Hash", Want: 8, Got: len(m.Hash), Row: i}
+		}
+		hashData[i] = m.Hash
+	}
+	return []entity.Column{
+		entity.NewColumnVarChar("CreatedBy", createdByData),
+		entity.NewColumnInt64("CreatedAt", createdAtData),
+		entity.NewColumnInt64("owner_Id", ownerIdData),
+		entity.NewColumnVarChar("owner_Team", ownerTeamData),
+		entity.NewColumnInt64("Id", idData),
+		entity.NewColumnVarChar("Title", titleData),
+		entity.NewColumnVarChar("Lang", langData),
+		entity.NewColumnFloat("Rank", rankData),
+		entity.NewColumnDouble("Weight", weightData),
+		entity.NewColumnInt32("Views", viewsData),
+		entity.NewColumnBool("Visible", visibleData),
+		entity.NewColumnVarChar("Kind", kindData),
+		entity.NewColumnInt64("PublishedAt", publishedAtData),
+		entity.NewColumnInt64("TTL", ttlData),
+		entity.NewColumnInt32("Shards", shardsData),
+		entity.NewColumnInt64("Size", sizeData),
+		entity.NewColumnFloatVector("Vector", 128, vectorData),
+		entity.NewColumnFloat16Vector("Thumb", 8, thumbData),
+		entity.NewColumnBinaryVector("Hash", 64, hashData),
+	}, nil
+}
+
+func docMilvusFill(models []Doc, columns []entity.Column) error {
+	for _, column := range columns {
+		if column.Len() != len(models) {
+			return fmt.Errorf("column '%s' length (%d) does not match model count (%d)", column.Name(), column.Len(), len(models))
+		}
+		switch column.Name() {
+		case "CreatedBy":
+			switch col := column.(type) {
+			case *entity.ColumnVarChar:
+				for i, x := range col.Data() {
+					models[i].Audit.CreatedBy = x
+				}
+			case *entity.ColumnString:
+				for i, x := range col.Data() {
+					models[i].Audit.CreatedBy = x
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'CreatedBy': expected string, got %T from Milvus", column)
+			}
+		case "CreatedAt":
+			switch col := column.(type) {
+			case *entity.ColumnInt64:
+				for i, x := range col.Data() {
+					models[i].Audit.CreatedAt = x
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'CreatedAt': expected int64, got %T from Milvus", column)
+			}
+		case "owner_Id":
+			switch col := column.(type) {
+			case *entity.ColumnInt64:
+				for i, x := range col.Data() {
+					models[i].Owner.Id = x
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'owner_Id': expected int64, got %T from Milvus", column)
+			}
+		case "owner_Team":
+			switch col := column.(type) {
+			case *entity.ColumnVarChar:
+				for i, x := range col.Data() {
+					models[i].Owner.Team = x
+				}
+			case *entity.ColumnString:
+				for i, x := range col.Data() {
+					models[i].Owner.Team = x
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'owner_Team': expected string, got %T from Milvus", column)
+			}
+		case "Id":
+			switch col := column.(type) {
+			case *entity.ColumnInt64:
+				for i, x := range col.Data() {
+					models[i].Id = x
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'Id': expected int64, got %T from Milvus", column)
+			}
+		case "Title":
+			switch col := column.(type) {
+			case *entity.ColumnVarChar:
+				for i, x := range col.Data() {
+					models[i].Title = x
+				}
+			case *entity.ColumnString:
+				for i, x := range col.Data() {
+					models[i].Title = x
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'Title': expected string, got %T from Milvus", column)
+			}
+		case "Lang":
+			switch col := column.(type) {
+			case *entity.ColumnVarChar:
+				for i, x := range col.Data() {
+					models[i].Lang = x
+				}
+			case *entity.ColumnString:
+				for i, x := range col.Data() {
+					models[i].Lang = x
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'Lang': expected string, got %T from Milvus", column)
+			}
+		case "Rank":
+			switch col := column.(type) {
+			case *entity.ColumnFloat:
+				for i, x := range col.Data() {
+					models[i].Rank = x
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'Rank': expected float32, got %T from Milvus", column)
+			}
+		case "Weight":
+			switch col := column.(type) {
+			case *entity.ColumnDouble:
+				for i, x := range col.Data() {
+					models[i].Weight = x
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'Weight': expected float64, got %T from Milvus", column)
+			}
+		case "Views":
+			switch col := column.(type) {
+			case *entity.ColumnInt32:
+				for i, x := range col.Data() {
+					models[i].Views = x
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'Views': expected int32, got %T from Milvus", column)
+			}
+		case "Visible":
+			switch col := column.(type) {
+			case *entity.ColumnBool:
+				for i, x := range col.Data() {
+					models[i].Visible = x
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'Visible': expected bool, got %T from Milvus", column)
+			}
+		case "Kind":
+			switch col := column.(type) {
+			case *entity.ColumnVarChar:
+				for i, x := range col.Data() {
+					models[i].Kind = DocKind(x)
+				}
+			case *entity.ColumnString:
+				for i, x := range col.Data() {
+					models[i].Kind = DocKind(x)
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'Kind': expected string, got %T from Milvus", column)
+			}
+		case "PublishedAt":
+			switch col := column.(type) {
+			case *entity.ColumnInt64:
+				for i, x := range col.Data() {
+					models[i].PublishedAt = qmilvus.EpochToTime(x, time.Second)
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'PublishedAt': expected int64, got %T from Milvus", column)
+			}
+		case "TTL":
+			switch col := column.(type) {
+			case *entity.ColumnInt64:
+				for i, x := range col.Data() {
+					models[i].TTL = time.Duration(x)
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'TTL': expected int64, got %T from Milvus", column)
+			}
+		case "Shards":
+			switch col := column.(type) {
+			case *entity.ColumnInt32:
+				for i, x := range col.Data() {
+					if x < 0 || x > math.MaxUint16 {
+						return fmt.Errorf("row %d field Shards: %d overflows uint16: %w", i, x, qmilvus.ErrInvalidArgument)
+					}
+					models[i].Shards = uint16(x)
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'Shards': expected int32, got %T from Milvus", column)
+			}
+		case "Size":
+			switch col := column.(type) {
+			case *entity.ColumnInt64:
+				for i, x := range col.Data() {
+					if x < 0 {
+						return fmt.Errorf("row %d field Size: %d overflows uint64: %w", i, x, qmilvus.ErrInvalidArgument)
+					}
+					models[i].Size = uint64(x)
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'Size': expected int64, got %T from Milvus", column)
+			}
+		case "Vector":
+			switch col := column.(type) {
+			case *entity.ColumnFloatVector:
+				for i, x := range col.Data() {
+					models[i].Vector = x
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'Vector': expected []float32, got %T from Milvus", column)
+			}
+		case "Thumb":
+			switch col := column.(type) {
+			case *entity.ColumnFloat16Vector:
+				for i, x := range col.Data() {
+					models[i].Thumb = qmilvus.Float16ToFloat32(x)
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'Thumb': expected []byte, got %T from Milvus", column)
+			}
+		case "Hash":
+			switch col := column.(type) {
+			case *entity.ColumnBinaryVector:
+				for i, x := range col.Data() {
+					models[i].Hash = x
+				}
+			default:
+				return fmt.Errorf("type mismatch for field 'Hash': expected []byte, got %T from Milvus", column)
+			}
+		}
+	}
+	return nil
+}
+
+func docMilvusColumnsOfPtrs(models []*Doc) ([]entity.Column, error) {
+	return docMilvusColumns(len(models), func(i int) *Doc { return models[i] })
+}
+
+func docMilvusColumnsOfValues(models []Doc) ([]entity.Column, error) {
+	return docMilvusColumns(len(models), func(i int) *Doc { return &models[i] })
+}
+
+func docMilvusModelsOfPtrs(count int, columns []entity.Column) ([]*Doc, error) {
+	values, err := docMilvusModelsOfValues(count, columns)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]*Doc, count)
+	for i := range values {
+		models[i] = &values[i]
+	}
+	return models, nil
+}
+
+func docMilvusModelsOfValues(count int, columns []entity.Column) ([]Doc, error) {
+	models := make([]Doc, count)
+	if err := docMilvusFill(models, columns); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
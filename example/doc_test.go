@@ -0,0 +1,116 @@
+package example
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/doptime/qmilvus"
+	"github.com/milvus-io/milvus-sdk-go/v2/client"
+	"github.com/milvus-io/milvus-sdk-go/v2/entity"
+)
+
+func docs(n int) []*Doc {
+	docs := make([]*Doc, n)
+	for i := range docs {
+		vector := make([]float32, 128)
+		for j := range vector {
+			vector[j] = rand.Float32()
+		}
+		docs[i] = &Doc{Id: int64(i), Title: fmt.Sprintf("doc %d", i), Lang: "en", Rank: rand.Float32(), Weight: rand.Float64(), Views: int32(i), Visible: i%2 == 0, Vector: vector,
+			Audit: Audit{CreatedBy: "importer", CreatedAt: int64(1700000000 + i)}, Owner: Ownership{Id: int64(i % 3), Team: "search"}}
+		docs[i].Kind, docs[i].PublishedAt, docs[i].TTL, docs[i].Shards, docs[i].Size = KindArticle, time.Unix(int64(1700000000+i), 0).UTC(), time.Duration(i)*time.Hour, uint16(i%4), uint64(i)<<20
+		docs[i].Hash = make([]byte, 8)
+		rand.Read(docs[i].Hash)
+		// multiples of 1/8 are exact in float16
+		docs[i].Thumb = make([]float32, 8)
+		for j := range docs[i].Thumb {
+			docs[i].Thumb[j] = float32(rand.Intn(64)) / 8
+		}
+	}
+	return docs
+}
+
+// collections built by the generated codec, and by reflection
+func collections() (generated, reflective *qmilvus.Collection[*Doc]) {
+	return qmilvus.NewCollection[*Doc]("localhost"), qmilvus.NewCollection[*Doc]("localhost").WithCodec(nil)
+}
+
+func TestCodecMatchesReflection(t *testing.T) {
+	generated, reflective := collections()
+	in := docs(16)
+	want, err := reflective.BuildColumns(in...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := generated.BuildColumns(in...)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("generated columns differ from the reflective ones, %v", err)
+	}
+
+	result := &client.SearchResult{ResultCount: len(in), Fields: got}
+	out, err := generated.ParseSearchResult(result)
+	if err != nil || !reflect.DeepEqual(out, in) {
+		t.Fatalf("generated models differ from the models written, %v", err)
+	}
+
+	// a Shards value out of the uint16 range is rejected by both
+	for i, column := range got {
+		if column.Name() == "Shards" {
+			shards := make([]int32, len(in))
+			shards[5] = 70000
+			got[i] = entity.NewColumnInt32("Shards", shards)
+		}
+	}
+	for _, c := range []*qmilvus.Collection[*Doc]{generated, reflective} {
+		if _, err = c.ParseSearchResult(result); !errors.Is(err, qmilvus.ErrInvalidArgument) {
+			t.Errorf("expected the overflow of Shards, got %v", err)
+		}
+	}
+
+	in[3].Vector = in[3].Vector[:5]
+	var dimErr *qmilvus.ErrDimMismatch
+	if _, err = generated.BuildColumns(in...); !errors.As(err, &dimErr) || dimErr.Row != 3 {
+		t.Errorf("expected a dim mismatch at row 3, got %v", err)
+	}
+}
+
+func BenchmarkBuildColumns(b *testing.B) {
+	generated, reflective := collections()
+	in := docs(1000)
+	for _, bench := range []struct {
+		name string
+		c    *qmilvus.Collection[*Doc]
+	}{{"reflect", reflective}, {"generated", generated}} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := bench.c.BuildColumns(in...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseSearchResult(b *testing.B) {
+	generated, reflective := collections()
+	columns, _ := generated.BuildColumns(docs(1000)...)
+	result := &client.SearchResult{ResultCount: 1000, Fields: columns}
+	for _, bench := range []struct {
+		name string
+		c    *qmilvus.Collection[*Doc]
+	}{{"reflect", reflective}, {"generated", generated}} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := bench.c.ParseSearchResult(result); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
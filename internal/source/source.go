@@ -0,0 +1,100 @@
+// Package source reads qmilvus models from go source, for the qmilvus commands.
+// the struct fields are mapped with the tag logic of NewCollection, qmilvus.SchemaField and qmilvus.FlattenField
+package source
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"github.com/doptime/qmilvus"
+	"golang.org/x/tools/go/packages"
+)
+
+// Load loads the package in dir with its types
+func Load(dir string) (*packages.Package, error) {
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedTypes | packages.NeedSyntax | packages.NeedImports | packages.NeedDeps, Dir: dir}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%d packages found in %s", len(pkgs), dir)
+	}
+	if len(pkgs[0].Errors) > 0 {
+		return nil, fmt.Errorf("load %s: %v", dir, pkgs[0].Errors[0])
+	}
+	return pkgs[0], nil
+}
+
+// LookupStruct returns the struct type typeName of pkg
+func LookupStruct(pkg *packages.Package, typeName string) (*types.Struct, error) {
+	obj := pkg.Types.Scope().Lookup(typeName)
+	if obj == nil {
+		return nil, fmt.Errorf("type %s not found in package %s", typeName, pkg.PkgPath)
+	}
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a struct", typeName)
+	}
+	return st, nil
+}
+
+// SchemaType returns t as SchemaField reads it, as NewCollection does: named types by their underlying type, time.Time as it is
+func SchemaType(t types.Type, qualifier types.Qualifier) string {
+	if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "time" && named.Obj().Name() == "Time" {
+		return "time.Time"
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		return "*" + SchemaType(u.Elem(), qualifier)
+	case *types.Slice:
+		return "[]" + SchemaType(u.Elem(), qualifier)
+	case *types.Basic:
+		return u.Name()
+	}
+	return types.TypeString(t, qualifier)
+}
+
+// Field is a leaf field of a model struct
+type Field struct {
+	Name     string // milvus field name, prefixed for the fields of nested structs
+	Path     string // selector of the struct field, e.g. Owner.Id
+	Var      *types.Var
+	Tag      reflect.StructTag
+	Indirect bool // reached through a pointer to a nested struct
+}
+
+// WalkStruct calls fn for every leaf field of st, the fields of flattened structs included, as NewCollection does
+func WalkStruct(st *types.Struct, qualifier types.Qualifier, fn func(f Field) error) error {
+	return walkStruct(st, Field{}, qualifier, fn)
+}
+
+func walkStruct(st *types.Struct, parent Field, qualifier types.Qualifier, fn func(f Field) error) error {
+	for i := 0; i < st.NumFields(); i++ {
+		f, tag := st.Field(i), reflect.StructTag(st.Tag(i))
+		nested, ptr := f.Type().Underlying(), false
+		if p, ok := nested.(*types.Pointer); ok {
+			nested, ptr = p.Elem().Underlying(), true
+		}
+		if nestedStruct, ok := nested.(*types.Struct); ok && !strings.HasSuffix(SchemaType(f.Type(), qualifier), "time.Time") {
+			nestedPrefix, flatten, err := qmilvus.FlattenField(qmilvus.StructField{Name: f.Name(), Type: types.TypeString(f.Type(), qualifier), Tag: tag}, f.Anonymous())
+			if err != nil {
+				return fmt.Errorf("%s: %w", f.Name(), err)
+			}
+			if !flatten {
+				continue
+			}
+			nestedParent := Field{Name: parent.Name + nestedPrefix, Path: parent.Path + f.Name() + ".", Indirect: parent.Indirect || ptr}
+			if err = walkStruct(nestedStruct, nestedParent, qualifier, fn); err != nil {
+				return fmt.Errorf("%s.%w", f.Name(), err)
+			}
+			continue
+		}
+		if err := fn(Field{Name: parent.Name + f.Name(), Path: parent.Path + f.Name(), Var: f, Tag: tag, Indirect: parent.Indirect}); err != nil {
+			return fmt.Errorf("%s: %w", f.Name(), err)
+		}
+	}
+	return nil
+}